@@ -0,0 +1,171 @@
+package gridneighborhoods
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Bounds is the inclusive bounding box of a set of cells. In JSON its corners use the
+// "row,column" text form of Position.
+type Bounds struct {
+	Min Position `json:"min"`
+	Max Position `json:"max"`
+}
+
+// Report summarizes the neighborhood union of a grid at one threshold. Its JSON field
+// names are stable, so cached reports can be read back with ReadReportJSON.
+type Report struct {
+	// Count is the number of unique covered cells
+	Count int `json:"count"`
+	// CoverageRatio is Count divided by the number of grid cells
+	CoverageRatio float64 `json:"coverage_ratio"`
+	// Bounds is the bounding box of the covered cells; zero when nothing is covered
+	Bounds Bounds `json:"bounds"`
+	// CentroidRow and CentroidColumn are the mean position of the covered cells
+	CentroidRow    float64 `json:"centroid_row"`
+	CentroidColumn float64 `json:"centroid_column"`
+	// OverlapCount is the number of cells covered by more than one positive cell
+	OverlapCount int `json:"overlap_count"`
+	// ComponentCount is the number of 4-connected regions of covered cells, connecting
+	// across the wrapped axes of the calculator's topology
+	ComponentCount int `json:"component_count"`
+}
+
+// WriteJSON writes the report as a single JSON object
+func (r *Report) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// ReadReportJSON reads a report written by WriteJSON
+func ReadReportJSON(r io.Reader) (*Report, error) {
+	report := &Report{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Analyze enumerates the neighborhoods once and fills a Report with the count, coverage
+// ratio, bounds, centroid, overlap count, and component count of the union. Excluded cells
+// are left out of every figure, as they are of CountNeighborhoodCells.
+func (nc *NeighborhoodCalculator) Analyze(grid *Grid, distanceThreshold int) (*Report, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	multiplicity := nc.CoverageMultiplicity(grid, distanceThreshold)
+	report := &Report{
+		Count:         len(multiplicity),
+		CoverageRatio: float64(len(multiplicity)) / float64(grid.CellCount()),
+	}
+	if report.Count == 0 {
+		return report, nil
+	}
+
+	first := true
+	rowSum, colSum := 0, 0
+	for pos, sources := range multiplicity {
+		if sources > 1 {
+			report.OverlapCount++
+		}
+		rowSum += pos.Row
+		colSum += pos.Column
+		if first {
+			report.Bounds = Bounds{Min: pos, Max: pos}
+			first = false
+			continue
+		}
+		report.Bounds.Min.Row = min(report.Bounds.Min.Row, pos.Row)
+		report.Bounds.Min.Column = min(report.Bounds.Min.Column, pos.Column)
+		report.Bounds.Max.Row = max(report.Bounds.Max.Row, pos.Row)
+		report.Bounds.Max.Column = max(report.Bounds.Max.Column, pos.Column)
+	}
+	report.CentroidRow = float64(rowSum) / float64(report.Count)
+	report.CentroidColumn = float64(colSum) / float64(report.Count)
+	report.ComponentCount = nc.countComponents(grid, multiplicity)
+	return report, nil
+}
+
+// Coverage is the neighborhood union of a grid at one threshold, with the figures callers
+// would otherwise recompute from the cell set
+type Coverage struct {
+	// Cells is the set of unique covered cells, without excluded cells
+	Cells map[Position]bool
+	// Count is len(Cells)
+	Count int
+	// Bounds is the bounding box of the covered cells; zero when nothing is covered
+	Bounds Bounds
+	// SourceSizes holds the clipped neighborhood size of each positive cell, in
+	// PositiveCells order. Like CountSingleNeighborhood, it includes excluded cells.
+	SourceSizes []int
+}
+
+// ComputeCoverage enumerates each positive cell's neighborhood once and fills a Coverage
+// with the union, its count and bounds, and the per-source neighborhood sizes
+func (nc *NeighborhoodCalculator) ComputeCoverage(grid *Grid, distanceThreshold int) (*Coverage, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	coverage := &Coverage{
+		Cells:       make(map[Position]bool),
+		SourceSizes: make([]int, len(grid.PositiveCells)),
+	}
+	excluded := grid.excludedCells()
+	for i, center := range grid.PositiveCells {
+		nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+			coverage.SourceSizes[i]++
+			if coverage.Cells[pos] || excluded[pos] {
+				return true
+			}
+			coverage.Cells[pos] = true
+			if len(coverage.Cells) == 1 {
+				coverage.Bounds = Bounds{Min: pos, Max: pos}
+				return true
+			}
+			coverage.Bounds.Min.Row = min(coverage.Bounds.Min.Row, pos.Row)
+			coverage.Bounds.Min.Column = min(coverage.Bounds.Min.Column, pos.Column)
+			coverage.Bounds.Max.Row = max(coverage.Bounds.Max.Row, pos.Row)
+			coverage.Bounds.Max.Column = max(coverage.Bounds.Max.Column, pos.Column)
+			return true
+		})
+	}
+	coverage.Count = len(coverage.Cells)
+	return coverage, nil
+}
+
+// countComponents counts the 4-connected regions of a cell set. Steps across a wrapped
+// axis of the calculator's topology connect, so a region crossing the seam counts once.
+func (nc *NeighborhoodCalculator) countComponents(grid *Grid, cells map[Position]int) int {
+	visited := make(map[Position]bool, len(cells))
+	components := 0
+	for start := range cells {
+		if visited[start] {
+			continue
+		}
+		components++
+		visited[start] = true
+		stack := []Position{start}
+		for len(stack) > 0 {
+			pos := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, adjacent := range pos.Neighbors4() {
+				neighbor, ok := nc.wrapPosition(grid, adjacent)
+				if !ok {
+					continue
+				}
+				if _, covered := cells[neighbor]; covered && !visited[neighbor] {
+					visited[neighbor] = true
+					stack = append(stack, neighbor)
+				}
+			}
+		}
+	}
+	return components
+}
@@ -0,0 +1,145 @@
+package gridneighborhoods_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestAnalyzeScenario3(t *testing.T) {
+	// Scenario 3: two separate 13-cell diamonds
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 7, Column: 7}})
+	report, err := NewNeighborhoodCalculator().Analyze(grid, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report.Count != 26 {
+		t.Errorf("Expected count 26, got %d", report.Count)
+	}
+	if report.CoverageRatio != 26.0/121.0 {
+		t.Errorf("Expected ratio %v, got %v", 26.0/121.0, report.CoverageRatio)
+	}
+	if report.Bounds != (Bounds{Min: Position{Row: 1, Column: 1}, Max: Position{Row: 9, Column: 9}}) {
+		t.Errorf("Unexpected bounds %+v", report.Bounds)
+	}
+	if report.CentroidRow != 5 || report.CentroidColumn != 5 {
+		t.Errorf("Expected centroid (5,5), got (%v,%v)", report.CentroidRow, report.CentroidColumn)
+	}
+	if report.OverlapCount != 0 {
+		t.Errorf("Expected no overlap, got %d", report.OverlapCount)
+	}
+	if report.ComponentCount != 2 {
+		t.Errorf("Expected 2 components, got %d", report.ComponentCount)
+	}
+}
+
+func TestAnalyzeScenario4(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	report, _ := NewNeighborhoodCalculator().Analyze(grid, 2)
+
+	if report.Count != 22 || report.OverlapCount != 4 || report.ComponentCount != 1 {
+		t.Errorf("Expected 22 cells, 4 overlapping, 1 component; got %+v", report)
+	}
+}
+
+func TestAnalyzeComponentsAcrossWrappedSeam(t *testing.T) {
+	// Sources on opposite edges touch through the seam once columns wrap
+	grid, _ := NewGrid(1, 10, []Position{{Row: 0, Column: 0}, {Row: 0, Column: 9}})
+	if report, _ := NewNeighborhoodCalculator().Analyze(grid, 0); report.ComponentCount != 2 {
+		t.Errorf("Expected 2 components on a plain strip, got %d", report.ComponentCount)
+	}
+	wrapped := NewNeighborhoodCalculator(WithTopology(Topology{WrapColumns: true}))
+	if report, _ := wrapped.Analyze(grid, 0); report.ComponentCount != 1 {
+		t.Errorf("Expected 1 component across the seam, got %d", report.ComponentCount)
+	}
+}
+
+func TestAnalyzeEmptyGrid(t *testing.T) {
+	grid, _ := NewGrid(10, 10, []Position{})
+	report, err := NewNeighborhoodCalculator().Analyze(grid, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *report != (Report{}) {
+		t.Errorf("Expected zero report, got %+v", report)
+	}
+}
+
+func TestComputeCoverageScenario4(t *testing.T) {
+	// Scenario 4: overlapping diamonds around (3,3) and (4,5)
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	coverage, err := calculator.ComputeCoverage(grid, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if coverage.Count != 22 || len(coverage.Cells) != 22 {
+		t.Errorf("Expected 22 cells, got count %d and %d in set", coverage.Count, len(coverage.Cells))
+	}
+	if missing, extra := DiffCoverage(calculator.GetNeighborhoodCells(grid, 2), coverage.Cells); len(missing) != 0 || len(extra) != 0 {
+		t.Errorf("Expected Cells to match GetNeighborhoodCells: missing %v, extra %v", missing, extra)
+	}
+	if coverage.Bounds != (Bounds{Min: Position{Row: 1, Column: 1}, Max: Position{Row: 6, Column: 7}}) {
+		t.Errorf("Unexpected bounds %+v", coverage.Bounds)
+	}
+	if !reflect.DeepEqual(coverage.SourceSizes, []int{13, 13}) {
+		t.Errorf("Expected source sizes [13 13], got %v", coverage.SourceSizes)
+	}
+}
+
+func TestComputeCoverageEdgesAndErrors(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	grid, _ := NewGrid(5, 5, nil)
+	coverage, _ := calculator.ComputeCoverage(grid, 3)
+	if coverage.Count != 0 || coverage.Bounds != (Bounds{}) || len(coverage.SourceSizes) != 0 {
+		t.Errorf("Expected empty coverage, got %+v", coverage)
+	}
+
+	// A corner source is clipped to 6 cells at N=2
+	grid, _ = NewGrid(5, 5, []Position{{Row: 0, Column: 0}})
+	coverage, _ = calculator.ComputeCoverage(grid, 2)
+	if !reflect.DeepEqual(coverage.SourceSizes, []int{6}) {
+		t.Errorf("Expected source sizes [6], got %v", coverage.SourceSizes)
+	}
+
+	if _, err := calculator.ComputeCoverage(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, err := calculator.ComputeCoverage(nil, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestReportJSONRoundTrip(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	report, _ := NewNeighborhoodCalculator().Analyze(grid, 2)
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, field := range []string{`"count":22`, `"bounds":{"min":"1,1","max":"6,7"}`, `"overlap_count":4`, `"component_count":1`} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("Expected %s in %s", field, buf.String())
+		}
+	}
+
+	loaded, err := ReadReportJSON(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if *loaded != *report {
+		t.Errorf("Round trip mismatch: expected %+v, got %+v", *report, *loaded)
+	}
+
+	if _, err := ReadReportJSON(strings.NewReader(`{"bounds":{"min":"1;1"}}`)); err == nil {
+		t.Error("Expected error for malformed position")
+	}
+}
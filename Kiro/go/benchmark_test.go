@@ -0,0 +1,115 @@
+package gridneighborhoods_test
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+// BenchmarkEnumerateNeighborhoodEdge stresses the clamped row/column ranges with a source
+// on the edge of a large grid, where most of the diamond falls outside the bounds
+func BenchmarkEnumerateNeighborhoodEdge(b *testing.B) {
+	grid, _ := NewGrid(2000, 2000, []Position{{Row: 1000, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.EnumerateNeighborhood(grid, Position{Row: 1000, Column: 0}, 300)
+	}
+}
+
+// BenchmarkEnumerateNeighborhoodCorner places the source in a corner, keeping only a quarter of the diamond
+func BenchmarkEnumerateNeighborhoodCorner(b *testing.B) {
+	grid, _ := NewGrid(2000, 2000, []Position{{Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.EnumerateNeighborhood(grid, Position{Row: 0, Column: 0}, 300)
+	}
+}
+
+// BenchmarkCountSingleSourceClosedForm measures the O(1) single-source fast path in
+// CountNeighborhoodCells for an edge source on a large grid
+func BenchmarkCountSingleSourceClosedForm(b *testing.B) {
+	grid, _ := NewGrid(2000, 2000, []Position{{Row: 1000, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.CountNeighborhoodCells(grid, 300)
+	}
+}
+
+// BenchmarkCountSingleSourceEnumerated measures the same neighborhood through full
+// enumeration, for comparison with BenchmarkCountSingleSourceClosedForm
+func BenchmarkCountSingleSourceEnumerated(b *testing.B) {
+	grid, _ := NewGrid(2000, 2000, []Position{{Row: 1000, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.CollectNeighborhoodCells(grid, 300)
+	}
+}
+
+// reportCellRate records how many neighborhood cells per second the benchmark produced,
+// given the cells covered by one iteration
+func reportCellRate(b *testing.B, cellsPerOp int) {
+	b.ReportMetric(float64(cellsPerOp)*float64(b.N)/b.Elapsed().Seconds(), "cells/s")
+}
+
+// BenchmarkSuiteSingleSourceSmall enumerates one full diamond in the 11x11 grid of Scenario 1
+func BenchmarkSuiteSingleSourceSmall(b *testing.B) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	cells := len(calculator.GetNeighborhoodCells(grid, 3))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.GetNeighborhoodCells(grid, 3)
+	}
+	reportCellRate(b, cells)
+}
+
+// BenchmarkSuiteSingleSourceHuge counts single neighborhoods on a grid of about 3.6 billion
+// cells, at a threshold the closed form handles and at one past the farthest cell. Both
+// must return without enumerating, or the benchmark would not finish.
+func BenchmarkSuiteSingleSourceHuge(b *testing.B) {
+	grid, err := NewGrid(60000, 60000, []Position{{Row: 100, Column: 30000}})
+	if err != nil {
+		b.Skipf("60000x60000 grid needs a 64-bit int: %v", err)
+	}
+	calculator := NewNeighborhoodCalculator()
+	center := grid.PositiveCells[0]
+	for _, tc := range []struct {
+		name      string
+		threshold int
+	}{
+		{"ClosedForm", 20000},
+		{"Saturated", 1 << 30},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			cells, _ := calculator.CountSingleNeighborhood(grid, center, tc.threshold)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				calculator.CountSingleNeighborhood(grid, center, tc.threshold)
+			}
+			reportCellRate(b, cells)
+		})
+	}
+}
+
+// BenchmarkSuiteManySourcesDense counts the union of 500 overlapping sources placed with a
+// fixed seed, so every run measures the same layout
+func BenchmarkSuiteManySourcesDense(b *testing.B) {
+	random := rand.New(rand.NewPCG(1, 2))
+	positions := make([]Position, 500)
+	for i := range positions {
+		positions[i] = Position{Row: random.IntN(300), Column: random.IntN(300)}
+	}
+	grid, _ := NewGrid(300, 300, positions)
+	calculator := NewNeighborhoodCalculator()
+	cells, _ := calculator.CountNeighborhoodCells(grid, 6)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculator.CountNeighborhoodCells(grid, 6)
+	}
+	reportCellRate(b, cells)
+}
@@ -0,0 +1,741 @@
+package gridneighborhoods
+
+import (
+	"iter"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// CoverageAtLeast reports whether the neighborhood union covers at least target cells.
+// Enumeration stops as soon as target unique cells have been seen. Excluded cells do not
+// count towards target.
+func (nc *NeighborhoodCalculator) CoverageAtLeast(grid *Grid, distanceThreshold, target int) bool {
+	if grid == nil || distanceThreshold < 0 {
+		return false
+	}
+	if target <= 0 {
+		return true
+	}
+
+	if len(grid.PositiveCells) == 0 {
+		return false
+	}
+	excluded := grid.excludedCells()
+
+	// Every positive cell covers itself, so distinct sources alone may already be enough
+	sources := make(map[Position]bool, len(grid.PositiveCells))
+	for _, pos := range grid.PositiveCells {
+		if !excluded[pos] {
+			sources[pos] = true
+		}
+	}
+	if target <= len(sources) {
+		return true
+	}
+
+	// Early termination: the whole grid is covered
+	if distanceThreshold >= grid.MaxManhattanDistance() {
+		return grid.CellCount()-grid.BlockedCellCount()-len(excluded) >= target
+	}
+
+	covered := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+			if !excluded[pos] {
+				covered[pos] = true
+			}
+			return len(covered) < target
+		})
+		if len(covered) >= target {
+			return true
+		}
+	}
+	return false
+}
+
+// ProbeCoverage returns the clipped neighborhood size a source placed at center would
+// have, without adding it to the grid
+func (nc *NeighborhoodCalculator) ProbeCoverage(grid *Grid, center Position, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if !nc.boundaryHandler.IsWithinBounds(center, grid) {
+		return 0, &PositionOutOfBoundsError{Position: center, Height: grid.Height, Width: grid.Width}
+	}
+	return len(nc.enumerateNeighborhood(grid, center, distanceThreshold)), nil
+}
+
+// IsCovered reports whether pos is in the neighborhood union, by checking its distance to
+// each positive cell rather than enumerating neighborhoods. Cells outside the grid,
+// blocked, or excluded are never covered.
+func (nc *NeighborhoodCalculator) IsCovered(grid *Grid, pos Position, distanceThreshold int) bool {
+	if grid == nil || distanceThreshold < 0 || !grid.IsValidPosition(pos) || grid.IsBlocked(pos) || slices.Contains(grid.Excluded, pos) {
+		return false
+	}
+	for _, center := range grid.PositiveCells {
+		if nc.gridDistance(grid, center, pos) <= distanceThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// EstimateCoverage estimates CountNeighborhoodCells by testing IsCovered on
+// ceil(sampleFraction * cells) cells drawn uniformly with replacement and scaling the
+// covered share back up to the grid. Draws come from the WithSampleSeed seed, so the
+// estimate is deterministic. A fraction of 1 counts the union exactly instead.
+func (nc *NeighborhoodCalculator) EstimateCoverage(grid *Grid, distanceThreshold int, sampleFraction float64) (estimate int, err error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if !(sampleFraction > 0 && sampleFraction <= 1) {
+		return 0, &InvalidSampleFractionError{Fraction: sampleFraction}
+	}
+
+	total := grid.CellCount()
+	if sampleFraction == 1 {
+		return len(nc.GetNeighborhoodCells(grid, distanceThreshold)), nil
+	}
+
+	samples := int(math.Ceil(sampleFraction * float64(total)))
+	random := rand.New(rand.NewPCG(nc.sampleSeed, 0))
+	hits := 0
+	for range samples {
+		index := random.IntN(total)
+		if nc.IsCovered(grid, Position{Row: index / grid.Width, Column: index % grid.Width}, distanceThreshold) {
+			hits++
+		}
+	}
+	return int(math.Round(float64(hits) / float64(samples) * float64(total))), nil
+}
+
+// ForEachCell calls fn once for every grid cell in row-major order (row 0..Height-1,
+// column 0..Width-1), reporting whether the cell is in the neighborhood union
+func (nc *NeighborhoodCalculator) ForEachCell(grid *Grid, distanceThreshold int, fn func(pos Position, covered bool)) {
+	if grid == nil {
+		return
+	}
+	covered := nc.GetNeighborhoodCells(grid, distanceThreshold)
+	for row := 0; row < grid.Height; row++ {
+		for col := 0; col < grid.Width; col++ {
+			pos := Position{Row: row, Column: col}
+			fn(pos, covered[pos])
+		}
+	}
+}
+
+// RowCoverage returns, for each row, how many of its cells are covered
+func (nc *NeighborhoodCalculator) RowCoverage(grid *Grid, distanceThreshold int) []int {
+	if grid == nil {
+		return nil
+	}
+	counts := make([]int, grid.Height)
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		counts[pos.Row]++
+	}
+	return counts
+}
+
+// ColumnCoverage returns, for each column, how many of its cells are covered
+func (nc *NeighborhoodCalculator) ColumnCoverage(grid *Grid, distanceThreshold int) []int {
+	if grid == nil {
+		return nil
+	}
+	counts := make([]int, grid.Width)
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		counts[pos.Column]++
+	}
+	return counts
+}
+
+// Interval is an inclusive range of columns [Start, End] within one row
+type Interval struct {
+	Start int
+	End   int
+}
+
+// CoverageRuns returns, for each row, the covered cells as maximal runs of consecutive
+// columns in increasing order. The run lengths sum to the neighborhood count.
+func (nc *NeighborhoodCalculator) CoverageRuns(grid *Grid, distanceThreshold int) [][]Interval {
+	if grid == nil {
+		return nil
+	}
+	columns := make([][]int, grid.Height)
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		columns[pos.Row] = append(columns[pos.Row], pos.Column)
+	}
+
+	runs := make([][]Interval, grid.Height)
+	for row, cols := range columns {
+		slices.Sort(cols)
+		for _, col := range cols {
+			if last := len(runs[row]) - 1; last >= 0 && runs[row][last].End == col-1 {
+				runs[row][last].End = col
+			} else {
+				runs[row] = append(runs[row], Interval{Start: col, End: col})
+			}
+		}
+	}
+	return runs
+}
+
+// OverlappingPairs returns every pair of positive cells whose neighborhoods share at least
+// one cell, i.e. whose Manhattan distance is at most 2*distanceThreshold. Pairs are ordered by
+// their index in grid.PositiveCells.
+func (nc *NeighborhoodCalculator) OverlappingPairs(grid *Grid, distanceThreshold int) [][2]Position {
+	if grid == nil {
+		return nil
+	}
+	var pairs [][2]Position
+	if distanceThreshold < 0 {
+		return pairs
+	}
+	for i := 0; i < len(grid.PositiveCells); i++ {
+		for j := i + 1; j < len(grid.PositiveCells); j++ {
+			first, second := grid.PositiveCells[i], grid.PositiveCells[j]
+			// Written as a difference so that thresholds near math.MaxInt cannot overflow
+			if nc.gridDistance(grid, first, second)-distanceThreshold <= distanceThreshold {
+				pairs = append(pairs, [2]Position{first, second})
+			}
+		}
+	}
+	return pairs
+}
+
+// CoverageRatioInWindow returns the fraction of cells covered within the inclusive window
+// [minRow,maxRow] x [minCol,maxCol], after clipping the window to the grid
+func (nc *NeighborhoodCalculator) CoverageRatioInWindow(grid *Grid, distanceThreshold int, minRow, minCol, maxRow, maxCol int) (float64, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if minRow > maxRow || minCol > maxCol {
+		return 0, &InvalidRectangleError{MinRow: minRow, MinCol: minCol, MaxRow: maxRow, MaxCol: maxCol}
+	}
+
+	clampedMinRow, clampedMaxRow := max(0, minRow), min(grid.Height-1, maxRow)
+	clampedMinCol, clampedMaxCol := max(0, minCol), min(grid.Width-1, maxCol)
+	if clampedMinRow > clampedMaxRow || clampedMinCol > clampedMaxCol {
+		// The window lies entirely outside the grid
+		return 0, &InvalidRectangleError{MinRow: minRow, MinCol: minCol, MaxRow: maxRow, MaxCol: maxCol}
+	}
+
+	covered := 0
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		if pos.Row >= clampedMinRow && pos.Row <= clampedMaxRow && pos.Column >= clampedMinCol && pos.Column <= clampedMaxCol {
+			covered++
+		}
+	}
+	total := (clampedMaxRow - clampedMinRow + 1) * (clampedMaxCol - clampedMinCol + 1)
+	return float64(covered) / float64(total), nil
+}
+
+// CountWithExternalSources counts the unique grid cells covered by the grid's positive cells
+// together with external sources. External centers may lie outside the grid; only the part
+// of their neighborhood that reaches into the grid is counted. Excluded cells are left out
+// as in CountNeighborhoodCells.
+func (nc *NeighborhoodCalculator) CountWithExternalSources(grid *Grid, external []Position, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	allCells := nc.GetNeighborhoodCells(grid, distanceThreshold)
+	excluded := grid.excludedCells()
+	for _, center := range external {
+		for pos := range nc.enumerateNeighborhood(grid, center, distanceThreshold) {
+			if !excluded[pos] {
+				allCells[pos] = true
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// CountNeighborhoodCellsTwoClass counts the unique cells covered by short-range sources
+// within shortN together with long-range sources within longN. The grid supplies the
+// dimensions, blocked mask, and excluded cells; its own positive cells are not used. Every
+// source must lie within the grid.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsTwoClass(grid *Grid, shortSources []Position, shortN int, longSources []Position, longN int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	for _, threshold := range []int{shortN, longN} {
+		if threshold < 0 {
+			return 0, &InvalidDistanceThresholdError{Threshold: threshold}
+		}
+	}
+	for _, center := range slices.Concat(shortSources, longSources) {
+		if !grid.IsValidPosition(center) {
+			return 0, &PositionOutOfBoundsError{Position: center, Height: grid.Height, Width: grid.Width}
+		}
+	}
+
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for _, class := range []struct {
+		sources   []Position
+		threshold int
+	}{{shortSources, shortN}, {longSources, longN}} {
+		for _, center := range class.sources {
+			for pos := range nc.enumerateNeighborhood(grid, center, class.threshold) {
+				if !excluded[pos] {
+					allCells[pos] = true
+				}
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// CountNeighborhoodCellsMasked counts the covered cells that are also set in allowed.
+// Allowed cells outside the grid, or blocked, are never covered and so never counted.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsMasked(grid *Grid, distanceThreshold int, allowed map[Position]bool) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	covered := nc.GetNeighborhoodCells(grid, distanceThreshold)
+	count := 0
+	for pos, ok := range allowed {
+		if ok && covered[pos] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountCoveredWhere counts the covered cells for which pred returns true. Only matching
+// cells are collected, so sparse predicates avoid materializing the whole union. pred may
+// be called more than once for a cell covered by several sources; a nil pred matches
+// every cell.
+func (nc *NeighborhoodCalculator) CountCoveredWhere(grid *Grid, distanceThreshold int, pred func(Position) bool) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if pred == nil {
+		return len(nc.GetNeighborhoodCells(grid, distanceThreshold)), nil
+	}
+
+	excluded := grid.excludedCells()
+	matching := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+			if !matching[pos] && !excluded[pos] && pred(pos) {
+				matching[pos] = true
+			}
+			return true
+		})
+	}
+	return len(matching), nil
+}
+
+// CountCoveredBorderCells counts the covered cells that lie on the grid's outer border:
+// row 0, row Height-1, column 0, or column Width-1
+func (nc *NeighborhoodCalculator) CountCoveredBorderCells(grid *Grid, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	count := 0
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		if pos.Row == 0 || pos.Row == grid.Height-1 || pos.Column == 0 || pos.Column == grid.Width-1 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetUncoveredCells returns every in-bounds cell that is not in the neighborhood union
+func (nc *NeighborhoodCalculator) GetUncoveredCells(grid *Grid, distanceThreshold int) map[Position]bool {
+	if grid == nil {
+		return make(map[Position]bool)
+	}
+	uncovered := make(map[Position]bool)
+	nc.ForEachCell(grid, distanceThreshold, func(pos Position, covered bool) {
+		if !covered {
+			uncovered[pos] = true
+		}
+	})
+	return uncovered
+}
+
+// PerSourceNeighborhoods yields each positive cell, in grid.PositiveCells order, together
+// with its clipped neighborhood. Nothing is yielded for a nil grid or negative threshold.
+func (nc *NeighborhoodCalculator) PerSourceNeighborhoods(grid *Grid, distanceThreshold int) iter.Seq2[Position, map[Position]bool] {
+	return func(yield func(Position, map[Position]bool) bool) {
+		if grid == nil || distanceThreshold < 0 {
+			return
+		}
+		for _, center := range grid.PositiveCells {
+			if !yield(center, nc.enumerateNeighborhood(grid, center, distanceThreshold)) {
+				return
+			}
+		}
+	}
+}
+
+// CoverageMatrix returns a dense Height x Width matrix where matrix[row][col] is true for
+// covered cells. A grid over the WithMaxGridCells limit yields a GridTooLargeError before
+// anything is allocated.
+func (nc *NeighborhoodCalculator) CoverageMatrix(grid *Grid, distanceThreshold int) ([][]bool, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if err := nc.checkGridCells(grid); err != nil {
+		return nil, err
+	}
+	matrix := make([][]bool, grid.Height)
+	for row := range matrix {
+		matrix[row] = make([]bool, grid.Width)
+	}
+	for pos := range nc.GetNeighborhoodCells(grid, distanceThreshold) {
+		matrix[pos.Row][pos.Column] = true
+	}
+	return matrix, nil
+}
+
+// CountNeighborhoodCellsDynamic counts the unique cells covered when each positive cell's
+// threshold is given by radiusFor(center). A negative radius returns an
+// InvalidDistanceThresholdError naming the offending center. Excluded cells are left out.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsDynamic(grid *Grid, radiusFor func(center Position) int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		radius := radiusFor(center)
+		if radius < 0 {
+			return 0, &InvalidDistanceThresholdError{Threshold: radius, Center: &center}
+		}
+		for pos := range nc.enumerateNeighborhood(grid, center, radius) {
+			if !excluded[pos] {
+				allCells[pos] = true
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// CompareConfigurations builds two grids of the same geometry from aPositives and
+// bPositives and splits their combined coverage into three disjoint sets: cells covered
+// only by A, only by B, and by both
+func (nc *NeighborhoodCalculator) CompareConfigurations(height, width int, aPositives, bPositives []Position, threshold int) (onlyA, onlyB, both map[Position]bool, err error) {
+	if threshold < 0 {
+		return nil, nil, nil, &InvalidDistanceThresholdError{Threshold: threshold}
+	}
+	gridA, err := NewGrid(height, width, aPositives)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	gridB, err := NewGrid(height, width, bPositives)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cellsA := nc.GetNeighborhoodCells(gridA, threshold)
+	cellsB := nc.GetNeighborhoodCells(gridB, threshold)
+	onlyA = make(map[Position]bool)
+	onlyB = make(map[Position]bool)
+	both = make(map[Position]bool)
+	for pos := range cellsA {
+		if cellsB[pos] {
+			both[pos] = true
+		} else {
+			onlyA[pos] = true
+		}
+	}
+	for pos := range cellsB {
+		if !cellsA[pos] {
+			onlyB[pos] = true
+		}
+	}
+	return onlyA, onlyB, both, nil
+}
+
+// CountAcrossGrids counts the neighborhood union of one source layout on grids of several
+// sizes, each given as [height, width]. Sources outside a grid are dropped from it when
+// dropOutOfBounds is set; otherwise they make the call fail with a PositionOutOfBoundsError.
+func (nc *NeighborhoodCalculator) CountAcrossGrids(sizes [][2]int, positives []Position, threshold int, dropOutOfBounds bool) ([]int, error) {
+	if threshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: threshold}
+	}
+
+	counts := make([]int, len(sizes))
+	for i, size := range sizes {
+		height, width := size[0], size[1]
+		sources := positives
+		if dropOutOfBounds {
+			sources = slices.DeleteFunc(slices.Clone(positives), func(pos Position) bool {
+				return pos.Row < 0 || pos.Row >= height || pos.Column < 0 || pos.Column >= width
+			})
+		}
+		grid, err := NewGrid(height, width, sources)
+		if err != nil {
+			return nil, err
+		}
+		if counts[i], err = nc.CountNeighborhoodCells(grid, threshold); err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// DiffCoverage compares two coverage sets and returns, sorted by row then column, the
+// cells in expected but not in actual and the cells in actual but not in expected.
+// Positions mapped to false count as absent.
+func DiffCoverage(expected, actual map[Position]bool) (missing, extra []Position) {
+	missingSet := make(map[Position]bool)
+	for pos, ok := range expected {
+		if ok && !actual[pos] {
+			missingSet[pos] = true
+		}
+	}
+	extraSet := make(map[Position]bool)
+	for pos, ok := range actual {
+		if ok && !expected[pos] {
+			extraSet[pos] = true
+		}
+	}
+	return sortedPositions(missingSet), sortedPositions(extraSet)
+}
+
+// CoverageEqual reports whether two coverage sets hold the same cells, however they were
+// computed. Positions mapped to false count as absent, as in DiffCoverage.
+func CoverageEqual(a, b map[Position]bool) bool {
+	for pos, ok := range a {
+		if ok && !b[pos] {
+			return false
+		}
+	}
+	for pos, ok := range b {
+		if ok && !a[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+// CoverageEqualMatrix reports whether cells holds exactly the true entries of matrix, a
+// row-major layout such as CoverageMatrix returns. A set cell outside the matrix makes
+// them unequal.
+func CoverageEqualMatrix(cells map[Position]bool, matrix [][]bool) bool {
+	covered := 0
+	for row := range matrix {
+		for col, ok := range matrix[row] {
+			if !ok {
+				continue
+			}
+			if !cells[Position{Row: row, Column: col}] {
+				return false
+			}
+			covered++
+		}
+	}
+	for _, ok := range cells {
+		if ok {
+			covered--
+		}
+	}
+	return covered == 0
+}
+
+// CoveredPairs returns the covered cells as [row, column] pairs sorted by row, then
+// column, ready for wire serialization
+func (nc *NeighborhoodCalculator) CoveredPairs(grid *Grid, distanceThreshold int) [][2]int {
+	positions := sortedPositions(nc.GetNeighborhoodCells(grid, distanceThreshold))
+	pairs := make([][2]int, len(positions))
+	for i, pos := range positions {
+		pairs[i] = [2]int{pos.Row, pos.Column}
+	}
+	return pairs
+}
+
+// CountCommonNeighborhoodCells counts the cells within distanceThreshold of every positive
+// cell, i.e. the intersection of all neighborhoods, leaving out excluded cells. A grid with
+// no positive cells has an empty intersection.
+func (nc *NeighborhoodCalculator) CountCommonNeighborhoodCells(grid *Grid, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if !grid.HasPositiveCells() {
+		return 0, nil
+	}
+
+	// Every common cell lies in the first neighborhood; test it against the other sources
+	excluded := grid.excludedCells()
+	count := 0
+	for pos := range nc.enumerateNeighborhood(grid, grid.PositiveCells[0], distanceThreshold) {
+		common := !excluded[pos]
+		for _, source := range grid.PositiveCells[1:] {
+			if nc.gridDistance(grid, pos, source) > distanceThreshold {
+				common = false
+				break
+			}
+		}
+		if common {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CoverageMultiplicity returns, for every covered cell, how many positive cells include it
+// in their neighborhood. Repeated positive cells each count. Under the Reflect boundary
+// mode every mirrored hit counts as well. Excluded cells are left out.
+func (nc *NeighborhoodCalculator) CoverageMultiplicity(grid *Grid, distanceThreshold int) map[Position]int {
+	multiplicity := make(map[Position]int)
+	if grid == nil {
+		return multiplicity
+	}
+	if nc.boundaryMode == Reflect {
+		for _, center := range grid.PositiveCells {
+			nc.forEachReflectedHit(grid, center, distanceThreshold, func(pos Position) {
+				multiplicity[pos]++
+			})
+		}
+	} else {
+		for _, center := range grid.PositiveCells {
+			for pos := range nc.enumerateNeighborhood(grid, center, distanceThreshold) {
+				multiplicity[pos]++
+			}
+		}
+	}
+	for pos := range grid.excludedCells() {
+		delete(multiplicity, pos)
+	}
+	return multiplicity
+}
+
+// FirstCoverer returns, for every covered cell, the earliest positive cell in
+// grid.PositiveCells order whose neighborhood contains it. Excluded cells have no owner.
+func (nc *NeighborhoodCalculator) FirstCoverer(grid *Grid, distanceThreshold int) map[Position]Position {
+	owners := make(map[Position]Position)
+	if grid == nil {
+		return owners
+	}
+	excluded := grid.excludedCells()
+	for _, center := range grid.PositiveCells {
+		nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+			if _, owned := owners[pos]; !owned && !excluded[pos] {
+				owners[pos] = center
+			}
+			return true
+		})
+	}
+	return owners
+}
+
+// LabeledPosition is a source position carrying a label
+type LabeledPosition struct {
+	Position Position
+	Label    string
+}
+
+// LabelCoverage returns, for every cell covered by sources, the label of the last source in
+// slice order whose neighborhood contains it, so later layers override earlier ones. The
+// grid supplies the dimensions, blocked mask, and excluded cells, which stay unlabeled; its
+// own positive cells are not used. Sources outside the grid contribute the part of their
+// neighborhood that reaches into it.
+func (nc *NeighborhoodCalculator) LabelCoverage(grid *Grid, sources []LabeledPosition, distanceThreshold int) map[Position]string {
+	labels := make(map[Position]string)
+	if grid == nil || distanceThreshold < 0 {
+		return labels
+	}
+	excluded := grid.excludedCells()
+	for _, source := range sources {
+		nc.scanNeighborhood(grid, source.Position, distanceThreshold, func(pos Position) bool {
+			if !excluded[pos] {
+				labels[pos] = source.Label
+			}
+			return true
+		})
+	}
+	return labels
+}
+
+// CoverageScore scores a configuration as the number of covered cells minus
+// overlapPenalty times the number of cells covered by more than one positive cell
+func (nc *NeighborhoodCalculator) CoverageScore(grid *Grid, distanceThreshold int, overlapPenalty float64) (float64, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	multiplicity := nc.CoverageMultiplicity(grid, distanceThreshold)
+	overlapCount := 0
+	for _, sources := range multiplicity {
+		if sources > 1 {
+			overlapCount++
+		}
+	}
+	return float64(len(multiplicity)) - overlapPenalty*float64(overlapCount), nil
+}
+
+// InfluenceField returns, for every covered cell, the sum over positive cells of
+// max(0, distanceThreshold - distance). Cells on the edge of every neighborhood that
+// covers them are present with influence 0; excluded cells are absent.
+func (nc *NeighborhoodCalculator) InfluenceField(grid *Grid, distanceThreshold int) map[Position]int {
+	field := make(map[Position]int)
+	if grid == nil {
+		return field
+	}
+	excluded := grid.excludedCells()
+	for _, center := range grid.PositiveCells {
+		for pos := range nc.enumerateNeighborhood(grid, center, distanceThreshold) {
+			if !excluded[pos] {
+				field[pos] += distanceThreshold - nc.gridDistance(grid, center, pos)
+			}
+		}
+	}
+	return field
+}
+
+// TotalWithDuplicates returns the sum of the clipped neighborhood sizes of all positive
+// cells, so cells in overlapping neighborhoods are counted once per covering source.
+// Like other per-source queries it includes excluded cells, so subtracting
+// CountNeighborhoodCells gives the total overlap only on grids without exclusions.
+func (nc *NeighborhoodCalculator) TotalWithDuplicates(grid *Grid, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	total := 0
+	for _, center := range grid.PositiveCells {
+		total += len(nc.enumerateNeighborhood(grid, center, distanceThreshold))
+	}
+	return total, nil
+}
@@ -0,0 +1,830 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestCoverageAtLeastScenario4(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	if !calculator.CoverageAtLeast(grid, 2, 22) {
+		t.Error("Expected coverage of at least 22")
+	}
+	if calculator.CoverageAtLeast(grid, 2, 23) {
+		t.Error("Expected coverage below 23")
+	}
+	if !calculator.CoverageAtLeast(grid, 0, 2) {
+		t.Error("Expected the two positive cells to satisfy target 2")
+	}
+	if calculator.CoverageAtLeast(grid, -1, 1) {
+		t.Error("Expected negative threshold to report false")
+	}
+}
+
+func TestCoverageAtLeastMatchesCount(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 20).Draw(t, "height")
+		width := rapid.IntRange(1, 20).Draw(t, "width")
+		numPositions := rapid.IntRange(0, 5).Draw(t, "numPositions")
+		positions := make([]Position, 0, numPositions)
+		for i := 0; i < numPositions; i++ {
+			row := rapid.IntRange(0, height-1).Draw(t, "pos_row")
+			col := rapid.IntRange(0, width-1).Draw(t, "pos_col")
+			positions = append(positions, Position{Row: row, Column: col})
+		}
+		distanceThreshold := rapid.IntRange(0, 40).Draw(t, "distanceThreshold")
+		target := rapid.IntRange(0, height*width+1).Draw(t, "target")
+
+		grid, _ := NewGrid(height, width, positions)
+		calculator := NewNeighborhoodCalculator()
+		count, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+
+		if got := calculator.CoverageAtLeast(grid, distanceThreshold, target); got != (count >= target) {
+			t.Fatalf("CoverageAtLeast(%d) = %v, but count is %d", target, got, count)
+		}
+	})
+}
+
+func TestProbeCoverage(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{})
+	calculator := NewNeighborhoodCalculator()
+
+	// Same geometry as Scenarios 1 and 2, probed without positive cells in the grid
+	if count, err := calculator.ProbeCoverage(grid, Position{Row: 5, Column: 5}, 3); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+	if count, _ := calculator.ProbeCoverage(grid, Position{Row: 5, Column: 1}, 3); count != 21 {
+		t.Errorf("Expected 21, got %d", count)
+	}
+	if len(grid.PositiveCells) != 0 {
+		t.Error("ProbeCoverage should not mutate the grid")
+	}
+
+	_, err := calculator.ProbeCoverage(grid, Position{Row: 11, Column: 0}, 3)
+	if _, ok := err.(*PositionOutOfBoundsError); !ok {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	_, err = calculator.ProbeCoverage(grid, Position{Row: 5, Column: 5}, -1)
+	if _, ok := err.(*InvalidDistanceThresholdError); !ok {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestForEachCellVisitsRowMajor(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	visited := 0
+	coveredCount := 0
+	calculator.ForEachCell(grid, 2, func(pos Position, covered bool) {
+		expected := Position{Row: visited / grid.Width, Column: visited % grid.Width}
+		if pos != expected {
+			t.Fatalf("Visit %d: expected %v, got %v", visited, expected, pos)
+		}
+		visited++
+		if covered {
+			coveredCount++
+		}
+	})
+
+	if visited != 121 {
+		t.Errorf("Expected 121 visits, got %d", visited)
+	}
+	if coveredCount != 22 {
+		t.Errorf("Expected 22 covered cells, got %d", coveredCount)
+	}
+}
+
+func TestRowAndColumnCoverageScenario1(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	expected := []int{0, 0, 1, 3, 5, 7, 5, 3, 1, 0, 0}
+	rows := calculator.RowCoverage(grid, 3)
+	columns := calculator.ColumnCoverage(grid, 3)
+	for i := range expected {
+		if rows[i] != expected[i] || columns[i] != expected[i] {
+			t.Fatalf("Index %d: expected %d, got row=%d column=%d", i, expected[i], rows[i], columns[i])
+		}
+	}
+}
+
+func TestRowAndColumnCoverageSumToCount(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		distanceThreshold := rapid.IntRange(0, 40).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+
+		rows := calculator.RowCoverage(grid, distanceThreshold)
+		columns := calculator.ColumnCoverage(grid, distanceThreshold)
+		if len(rows) != grid.Height || len(columns) != grid.Width {
+			t.Fatalf("Expected %d rows and %d columns, got %d and %d", grid.Height, grid.Width, len(rows), len(columns))
+		}
+
+		rowSum, columnSum := 0, 0
+		for _, c := range rows {
+			rowSum += c
+		}
+		for _, c := range columns {
+			columnSum += c
+		}
+		if rowSum != count || columnSum != count {
+			t.Fatalf("Expected sums of %d, got rows=%d columns=%d", count, rowSum, columnSum)
+		}
+	})
+}
+
+func TestOverlappingPairs(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4 overlaps, which is why it counts 22 instead of 26
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	pairs := calculator.OverlappingPairs(grid, 2)
+	if len(pairs) != 1 || pairs[0] != [2]Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}} {
+		t.Errorf("Expected the single Scenario 4 pair, got %v", pairs)
+	}
+
+	// Scenario 3 does not overlap
+	grid, _ = NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 7, Column: 7}})
+	if pairs := calculator.OverlappingPairs(grid, 2); len(pairs) != 0 {
+		t.Errorf("Expected no pairs, got %v", pairs)
+	}
+
+	// Scenario 14: all three corner cells overlap each other
+	grid, _ = NewGrid(11, 11, []Position{{Row: 10, Column: 9}, {Row: 9, Column: 10}, {Row: 10, Column: 10}})
+	if pairs := calculator.OverlappingPairs(grid, 3); len(pairs) != 3 {
+		t.Errorf("Expected 3 pairs, got %v", pairs)
+	}
+}
+
+func TestCoverageRatioInWindow(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// The 3x3 window around the source is fully inside the N=3 diamond
+	ratio, err := calculator.CoverageRatioInWindow(grid, 3, 4, 4, 6, 6)
+	if err != nil || ratio != 1 {
+		t.Errorf("Expected 1, got %v (err=%v)", ratio, err)
+	}
+
+	// The whole grid, given with out-of-bounds corners, clamps to 25/121
+	ratio, _ = calculator.CoverageRatioInWindow(grid, 3, -5, -5, 50, 50)
+	if ratio != 25.0/121.0 {
+		t.Errorf("Expected %v, got %v", 25.0/121.0, ratio)
+	}
+
+	// Column 5 (rows 0..10) holds 7 covered cells
+	ratio, _ = calculator.CoverageRatioInWindow(grid, 3, 0, 5, 10, 5)
+	if ratio != 7.0/11.0 {
+		t.Errorf("Expected %v, got %v", 7.0/11.0, ratio)
+	}
+
+	if _, err := calculator.CoverageRatioInWindow(grid, 3, 6, 6, 4, 4); err == nil {
+		t.Error("Expected error for inverted window")
+	}
+	if _, err := calculator.CoverageRatioInWindow(grid, 3, 20, 20, 30, 30); err == nil {
+		t.Error("Expected error for window outside the grid")
+	}
+}
+
+func TestCountWithExternalSources(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{})
+	calculator := NewNeighborhoodCalculator()
+
+	// A source one column left of the grid at row 5 reaches columns 0..2 with N=3
+	count, err := calculator.CountWithExternalSources(grid, []Position{{Row: 5, Column: -1}}, 3)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 5+3+1 {
+		t.Errorf("Expected 9, got %d", count)
+	}
+
+	// A source too far away contributes nothing
+	if count, _ := calculator.CountWithExternalSources(grid, []Position{{Row: -10, Column: -10}}, 3); count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+
+	// External sources union with the grid's own positive cells (Scenario 4 split in two)
+	grid, _ = NewGrid(11, 11, []Position{{Row: 3, Column: 3}})
+	if count, _ := calculator.CountWithExternalSources(grid, []Position{{Row: 4, Column: 5}}, 2); count != 22 {
+		t.Errorf("Expected 22, got %d", count)
+	}
+}
+
+func TestGetUncoveredCells(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// No positive cells: everything is uncovered
+	empty, _ := NewGrid(10, 10, []Position{})
+	if uncovered := calculator.GetUncoveredCells(empty, 3); len(uncovered) != 100 {
+		t.Errorf("Expected 100 uncovered cells, got %d", len(uncovered))
+	}
+
+	// Excessive threshold: nothing is uncovered
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+	if uncovered := calculator.GetUncoveredCells(grid, 30); len(uncovered) != 0 {
+		t.Errorf("Expected no uncovered cells, got %d", len(uncovered))
+	}
+
+	// Scenario 24: 121 - 85 cells remain uncovered and are disjoint from the union
+	uncovered := calculator.GetUncoveredCells(grid, 12)
+	if len(uncovered) != 121-85 {
+		t.Errorf("Expected %d uncovered cells, got %d", 121-85, len(uncovered))
+	}
+	for pos := range calculator.GetNeighborhoodCells(grid, 12) {
+		if uncovered[pos] {
+			t.Fatalf("Covered cell %v reported as uncovered", pos)
+		}
+	}
+}
+
+func TestPerSourceNeighborhoods(t *testing.T) {
+	// Scenario 13: opposite corners, each covering 10 cells
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}, {Row: 10, Column: 10}})
+	calculator := NewNeighborhoodCalculator()
+
+	var sources []Position
+	for source, neighborhood := range calculator.PerSourceNeighborhoods(grid, 3) {
+		sources = append(sources, source)
+		if len(neighborhood) != 10 {
+			t.Errorf("Source %v: expected 10 cells, got %d", source, len(neighborhood))
+		}
+		if !neighborhood[source] {
+			t.Errorf("Source %v should be in its own neighborhood", source)
+		}
+	}
+	if len(sources) != 2 || sources[0] != grid.PositiveCells[0] || sources[1] != grid.PositiveCells[1] {
+		t.Errorf("Expected sources in grid order, got %v", sources)
+	}
+
+	// Stopping early is honored
+	visits := 0
+	for range calculator.PerSourceNeighborhoods(grid, 3) {
+		visits++
+		break
+	}
+	if visits != 1 {
+		t.Errorf("Expected 1 visit, got %d", visits)
+	}
+}
+
+func TestCoverageMatrix(t *testing.T) {
+	// A corner source on a 2x3 grid with N=1 covers itself and its two neighbors
+	grid, _ := NewGrid(2, 3, []Position{{Row: 0, Column: 0}})
+	matrix, err := NewNeighborhoodCalculator().CoverageMatrix(grid, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := [][]bool{
+		{true, true, false},
+		{true, false, false},
+	}
+	if len(matrix) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(matrix))
+	}
+	for row := range expected {
+		if len(matrix[row]) != 3 {
+			t.Fatalf("Row %d: expected 3 columns, got %d", row, len(matrix[row]))
+		}
+		for col := range expected[row] {
+			if matrix[row][col] != expected[row][col] {
+				t.Errorf("Cell (%d,%d): expected %v, got %v", row, col, expected[row][col], matrix[row][col])
+			}
+		}
+	}
+
+	if _, err := NewNeighborhoodCalculator().CoverageMatrix(nil, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := NewNeighborhoodCalculator().CoverageMatrix(grid, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestCountNeighborhoodCellsDynamic(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 7, Column: 7}})
+	calculator := NewNeighborhoodCalculator()
+
+	// A constant radius matches CountNeighborhoodCells (Scenario 3)
+	count, err := calculator.CountNeighborhoodCellsDynamic(grid, func(Position) int { return 2 })
+	if err != nil || count != 26 {
+		t.Errorf("Expected 26, got %d (err=%v)", count, err)
+	}
+
+	// Per-source radii: 13 cells for N=2 at (3,3), 1 cell for N=0 at (7,7)
+	count, _ = calculator.CountNeighborhoodCellsDynamic(grid, func(center Position) int {
+		if center.Row == 3 {
+			return 2
+		}
+		return 0
+	})
+	if count != 14 {
+		t.Errorf("Expected 14, got %d", count)
+	}
+
+	_, err = calculator.CountNeighborhoodCellsDynamic(grid, func(center Position) int {
+		if center.Row == 7 {
+			return -1
+		}
+		return 2
+	})
+	thresholdErr, ok := err.(*InvalidDistanceThresholdError)
+	if !ok || thresholdErr.Center == nil || *thresholdErr.Center != (Position{Row: 7, Column: 7}) {
+		t.Errorf("Expected InvalidDistanceThresholdError naming (7,7), got %v", err)
+	}
+}
+
+func TestCompareConfigurations(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4 sources split into A and B: 13 + 13 cells sharing 4
+	onlyA, onlyB, both, err := calculator.CompareConfigurations(11, 11,
+		[]Position{{Row: 3, Column: 3}}, []Position{{Row: 4, Column: 5}}, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(onlyA) != 9 || len(onlyB) != 9 || len(both) != 4 {
+		t.Errorf("Expected 9/9/4, got %d/%d/%d", len(onlyA), len(onlyB), len(both))
+	}
+	for pos := range both {
+		if onlyA[pos] || onlyB[pos] {
+			t.Fatalf("Cell %v appears in more than one set", pos)
+		}
+	}
+
+	if _, _, _, err := calculator.CompareConfigurations(11, 11, nil, []Position{{Row: 11, Column: 0}}, 2); err == nil {
+		t.Error("Expected error for out-of-bounds B position")
+	}
+	if _, _, _, err := calculator.CompareConfigurations(0, 11, nil, nil, 2); err == nil {
+		t.Error("Expected error for invalid dimensions")
+	}
+}
+
+func TestCoveredPairsSorted(t *testing.T) {
+	grid, _ := NewGrid(2, 2, []Position{{Row: 0, Column: 1}})
+	pairs := NewNeighborhoodCalculator().CoveredPairs(grid, 1)
+
+	expected := [][2]int{{0, 0}, {0, 1}, {1, 1}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, pairs)
+	}
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, pairs)
+		}
+	}
+}
+
+func TestCountCommonNeighborhoodCells(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// A single source intersects with itself (Scenario 1)
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	if count, err := calculator.CountCommonNeighborhoodCells(grid, 3); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+
+	// Far-apart sources share nothing (Scenario 3)
+	grid, _ = NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 7, Column: 7}})
+	if count, _ := calculator.CountCommonNeighborhoodCells(grid, 2); count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+
+	// Scenario 4 shares 26 - 22 = 4 cells
+	grid, _ = NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	if count, _ := calculator.CountCommonNeighborhoodCells(grid, 2); count != 4 {
+		t.Errorf("Expected 4, got %d", count)
+	}
+
+	empty, _ := NewGrid(10, 10, []Position{})
+	if count, _ := calculator.CountCommonNeighborhoodCells(empty, 3); count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+}
+
+func TestCoverageMultiplicityAndScore(t *testing.T) {
+	// Scenario 4: 22 covered cells, 4 of them covered twice
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	multiplicity := calculator.CoverageMultiplicity(grid, 2)
+	if len(multiplicity) != 22 {
+		t.Errorf("Expected 22 covered cells, got %d", len(multiplicity))
+	}
+	if multiplicity[Position{Row: 3, Column: 4}] != 2 || multiplicity[Position{Row: 3, Column: 3}] != 1 {
+		t.Errorf("Unexpected multiplicities %v", multiplicity)
+	}
+
+	score, err := calculator.CoverageScore(grid, 2, 0.5)
+	if err != nil || score != 22-0.5*4 {
+		t.Errorf("Expected %v, got %v (err=%v)", 22-0.5*4, score, err)
+	}
+	if score, _ := calculator.CoverageScore(grid, 2, 0); score != 22 {
+		t.Errorf("Expected 22 with no penalty, got %v", score)
+	}
+	if _, err := calculator.CoverageScore(grid, -1, 1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+}
+
+func TestInfluenceField(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	field := NewNeighborhoodCalculator().InfluenceField(grid, 2)
+
+	if len(field) != 22 {
+		t.Errorf("Expected 22 cells in the field, got %d", len(field))
+	}
+	// (3,3) gets 2 from itself and 0 from (4,5), which is 3 away
+	if field[Position{Row: 3, Column: 3}] != 2 {
+		t.Errorf("Expected 2 at (3,3), got %d", field[Position{Row: 3, Column: 3}])
+	}
+	// (3,4) and (4,4) are 1 from one source and 2 from the other
+	if field[Position{Row: 3, Column: 4}] != 1 || field[Position{Row: 4, Column: 4}] != 1 {
+		t.Errorf("Expected 1 at shared cells, got %d and %d", field[Position{Row: 3, Column: 4}], field[Position{Row: 4, Column: 4}])
+	}
+	// Edge cells are present with zero influence
+	if value, ok := field[Position{Row: 1, Column: 3}]; !ok || value != 0 {
+		t.Errorf("Expected edge cell (1,3) with influence 0, got %d (present=%v)", value, ok)
+	}
+}
+
+func TestTotalWithDuplicatesScenario4(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	total, err := NewNeighborhoodCalculator().TotalWithDuplicates(grid, 2)
+	if err != nil || total != 26 {
+		t.Errorf("Expected 26, got %d (err=%v)", total, err)
+	}
+}
+
+func TestCountNeighborhoodCellsTwoClass(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{})
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4's sources with N=2 each cover 22 cells
+	count, err := calculator.CountNeighborhoodCellsTwoClass(grid, []Position{{Row: 3, Column: 3}}, 2, []Position{{Row: 4, Column: 5}}, 2)
+	if err != nil || count != 22 {
+		t.Errorf("Expected 22, got %d (err=%v)", count, err)
+	}
+
+	// A short-range source inside a long-range diamond adds nothing
+	count, _ = calculator.CountNeighborhoodCellsTwoClass(grid, []Position{{Row: 5, Column: 6}}, 1, []Position{{Row: 5, Column: 5}}, 3)
+	if count != 25 {
+		t.Errorf("Expected 25, got %d", count)
+	}
+
+	// Disjoint classes add up: N=0 point plus N=1 diamond
+	count, _ = calculator.CountNeighborhoodCellsTwoClass(grid, []Position{{Row: 0, Column: 0}}, 0, []Position{{Row: 5, Column: 5}}, 1)
+	if count != 6 {
+		t.Errorf("Expected 6, got %d", count)
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	if _, err := calculator.CountNeighborhoodCellsTwoClass(grid, nil, 1, []Position{{Row: 11, Column: 0}}, 1); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountNeighborhoodCellsTwoClass(grid, nil, 1, nil, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestCoverageRuns(t *testing.T) {
+	// Scenario 4: two overlapping N=2 diamonds at (3,3) and (4,5)
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	runs := calculator.CoverageRuns(grid, 2)
+	if len(runs) != 11 {
+		t.Fatalf("Expected 11 rows, got %d", len(runs))
+	}
+	expected := map[int][]Interval{
+		1: {{Start: 3, End: 3}},
+		2: {{Start: 2, End: 5}},
+		3: {{Start: 1, End: 6}},
+		4: {{Start: 2, End: 7}},
+		5: {{Start: 3, End: 6}},
+		6: {{Start: 5, End: 5}},
+	}
+	total := 0
+	for row, rowRuns := range runs {
+		if !slices.Equal(rowRuns, expected[row]) {
+			t.Errorf("Row %d: expected %v, got %v", row, expected[row], rowRuns)
+		}
+		for _, run := range rowRuns {
+			total += run.End - run.Start + 1
+		}
+	}
+	if total != 22 {
+		t.Errorf("Expected run lengths to sum to 22, got %d", total)
+	}
+
+	// A blocked cell splits a run
+	grid.AddBlockedRect(3, 4, 3, 4)
+	if got := calculator.CoverageRuns(grid, 2)[3]; !slices.Equal(got, []Interval{{Start: 1, End: 3}, {Start: 5, End: 6}}) {
+		t.Errorf("Expected row 3 split around the blocked cell, got %v", got)
+	}
+	if calculator.CoverageRuns(nil, 2) != nil {
+		t.Error("Expected nil for nil grid")
+	}
+}
+
+func TestCountNeighborhoodCellsMasked(t *testing.T) {
+	// Scenario 2: the clipped diamond at (5,1) with N=3 covers 21 cells
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 1}})
+	calculator := NewNeighborhoodCalculator()
+
+	allowed := map[Position]bool{
+		{Row: 5, Column: 0}:  true, // covered
+		{Row: 5, Column: 4}:  true, // covered, on the diamond's tip
+		{Row: 5, Column: 5}:  true, // out of reach
+		{Row: 5, Column: -2}: true, // within distance but outside the grid
+		{Row: 6, Column: 1}:  false,
+	}
+	if count, err := calculator.CountNeighborhoodCellsMasked(grid, 3, allowed); err != nil || count != 2 {
+		t.Errorf("Expected 2, got %d (err=%v)", count, err)
+	}
+
+	everything := make(map[Position]bool)
+	for row := 0; row < 11; row++ {
+		for col := 0; col < 11; col++ {
+			everything[Position{Row: row, Column: col}] = true
+		}
+	}
+	if count, _ := calculator.CountNeighborhoodCellsMasked(grid, 3, everything); count != 21 {
+		t.Errorf("Expected a full allow-list to give 21, got %d", count)
+	}
+	if count, _ := calculator.CountNeighborhoodCellsMasked(grid, 3, nil); count != 0 {
+		t.Errorf("Expected 0 for an empty allow-list, got %d", count)
+	}
+}
+
+func TestFirstCoverer(t *testing.T) {
+	// Scenario 4: (3,3) is listed first, so it owns the cells both diamonds share
+	first, second := Position{Row: 3, Column: 3}, Position{Row: 4, Column: 5}
+	grid, _ := NewGrid(11, 11, []Position{first, second})
+	calculator := NewNeighborhoodCalculator()
+
+	owners := calculator.FirstCoverer(grid, 2)
+	if len(owners) != 22 {
+		t.Fatalf("Expected 22 covered cells, got %d", len(owners))
+	}
+	ownedBySecond := 0
+	for cell, owner := range owners {
+		if owner == second {
+			ownedBySecond++
+			if cell.ManhattanDistance(first) <= 2 {
+				t.Errorf("Cell %v is within reach of the first source but owned by the second", cell)
+			}
+		}
+	}
+	if ownedBySecond != 9 {
+		t.Errorf("Expected the second source to own 9 cells, got %d", ownedBySecond)
+	}
+
+	// Reversing the source order transfers the shared cells
+	reversed, _ := NewGrid(11, 11, []Position{second, first})
+	if owner := calculator.FirstCoverer(reversed, 2)[Position{Row: 3, Column: 4}]; owner != second {
+		t.Errorf("Expected (3,4) to be owned by %v, got %v", second, owner)
+	}
+}
+
+func TestLabelCoverageLaterSourcesWin(t *testing.T) {
+	grid, _ := NewGrid(11, 11, nil)
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4 geometry: the diamonds of (3,3) and (4,5) share 4 cells with N=2
+	sources := []LabeledPosition{
+		{Position: Position{Row: 3, Column: 3}, Label: "base"},
+		{Position: Position{Row: 4, Column: 5}, Label: "overlay"},
+	}
+	labels := calculator.LabelCoverage(grid, sources, 2)
+	if len(labels) != 22 {
+		t.Fatalf("Expected 22 labeled cells, got %d", len(labels))
+	}
+	counts := map[string]int{}
+	for _, label := range labels {
+		counts[label]++
+	}
+	if counts["base"] != 9 || counts["overlay"] != 13 {
+		t.Errorf("Expected base=9 overlay=13, got %v", counts)
+	}
+	if labels[Position{Row: 3, Column: 4}] != "overlay" {
+		t.Errorf("Expected shared cell (3,4) labeled overlay, got %q", labels[Position{Row: 3, Column: 4}])
+	}
+
+	if got := calculator.LabelCoverage(grid, sources, -1); len(got) != 0 {
+		t.Errorf("Expected no labels for a negative threshold, got %v", got)
+	}
+}
+
+func TestCountCoveredBorderCells(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// A corner source at N=2 covers 6 cells; only the diagonal (1,1) is off the border
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+	if count, err := calculator.CountCoveredBorderCells(grid, 2); err != nil || count != 5 {
+		t.Errorf("Expected 5, got %d (err=%v)", count, err)
+	}
+
+	// A centered source that does not reach any edge
+	grid, _ = NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	if count, _ := calculator.CountCoveredBorderCells(grid, 4); count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+
+	// In a single-row grid every covered cell is on the border
+	grid, _ = NewGrid(1, 10, []Position{{Row: 0, Column: 4}})
+	if count, _ := calculator.CountCoveredBorderCells(grid, 2); count != 5 {
+		t.Errorf("Expected 5, got %d", count)
+	}
+
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountCoveredBorderCells(grid, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+	if _, err := calculator.CountCoveredBorderCells(nil, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestIsCoveredMatchesUnion(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		distanceThreshold := rapid.IntRange(0, 20).Draw(t, "distanceThreshold")
+		calculator := NewNeighborhoodCalculator()
+		cells := calculator.GetNeighborhoodCells(grid, distanceThreshold)
+		for row := -1; row <= grid.Height; row++ {
+			for col := -1; col <= grid.Width; col++ {
+				pos := Position{Row: row, Column: col}
+				if calculator.IsCovered(grid, pos, distanceThreshold) != cells[pos] {
+					t.Fatalf("IsCovered(%v) disagrees with GetNeighborhoodCells", pos)
+				}
+			}
+		}
+	})
+}
+
+func TestEstimateCoverage(t *testing.T) {
+	// Scenario 4 union of 22 cells in an 11x11 grid
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator(WithSampleSeed(7))
+
+	if estimate, err := calculator.EstimateCoverage(grid, 2, 1); err != nil || estimate != 22 {
+		t.Errorf("Expected exact 22 at fraction 1, got %d (err=%v)", estimate, err)
+	}
+
+	first, _ := calculator.EstimateCoverage(grid, 2, 0.5)
+	second, _ := calculator.EstimateCoverage(grid, 2, 0.5)
+	if first != second {
+		t.Errorf("Expected a fixed seed to give the same estimate, got %d and %d", first, second)
+	}
+	if first < 0 || first > grid.CellCount() {
+		t.Errorf("Estimate %d is outside [0, %d]", first, grid.CellCount())
+	}
+
+	// A large sample on a larger grid lands near the true count
+	large, _ := NewGrid(200, 200, []Position{{Row: 50, Column: 50}, {Row: 150, Column: 120}})
+	exact, _ := calculator.CountNeighborhoodCells(large, 30)
+	estimate, _ := calculator.EstimateCoverage(large, 30, 0.5)
+	if diff := estimate - exact; diff < -exact/10 || diff > exact/10 {
+		t.Errorf("Expected estimate within 10%% of %d, got %d", exact, estimate)
+	}
+
+	var fractionErr *InvalidSampleFractionError
+	for _, fraction := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if _, err := calculator.EstimateCoverage(grid, 2, fraction); !errors.As(err, &fractionErr) {
+			t.Errorf("Fraction %v: expected InvalidSampleFractionError, got %v", fraction, err)
+		}
+	}
+	if _, err := calculator.EstimateCoverage(nil, 2, 0.5); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestDiffCoverage(t *testing.T) {
+	expected := map[Position]bool{{Row: 2, Column: 1}: true, {Row: 0, Column: 3}: true, {Row: 1, Column: 1}: true, {Row: 5, Column: 5}: false}
+	actual := map[Position]bool{{Row: 1, Column: 1}: true, {Row: 4, Column: 0}: true, {Row: 3, Column: 9}: true, {Row: 0, Column: 3}: false}
+
+	missing, extra := DiffCoverage(expected, actual)
+	if want := []Position{{Row: 0, Column: 3}, {Row: 2, Column: 1}}; !slices.Equal(missing, want) {
+		t.Errorf("Expected missing %v, got %v", want, missing)
+	}
+	if want := []Position{{Row: 3, Column: 9}, {Row: 4, Column: 0}}; !slices.Equal(extra, want) {
+		t.Errorf("Expected extra %v, got %v", want, extra)
+	}
+
+	missing, extra = DiffCoverage(expected, expected)
+	if len(missing) != 0 || len(extra) != 0 {
+		t.Errorf("Expected no differences, got missing %v and extra %v", missing, extra)
+	}
+}
+
+func TestCountAcrossGrids(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+	positives := []Position{{Row: 3, Column: 3}, {Row: 8, Column: 8}}
+	sizes := [][2]int{{11, 11}, {5, 5}, {4, 11}}
+
+	// At 5x5 and 4x11 the (8,8) source falls outside and is dropped. The remaining diamond
+	// loses its two tips past row 4 and column 4 at 5x5, and the two rows past row 3 at 4x11.
+	counts, err := calculator.CountAcrossGrids(sizes, positives, 2, true)
+	if err != nil || !slices.Equal(counts, []int{26, 11, 9}) {
+		t.Errorf("Expected [26 11 9], got %v (err=%v)", counts, err)
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	if _, err := calculator.CountAcrossGrids(sizes, positives, 2, false); !errors.As(err, &boundsErr) || boundsErr.Height != 5 {
+		t.Errorf("Expected PositionOutOfBoundsError for the 5x5 grid, got %v", err)
+	}
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, err := calculator.CountAcrossGrids([][2]int{{0, 3}}, positives, 2, true); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountAcrossGrids(sizes, positives, -1, true); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestCountCoveredWhere(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// Of the 13-cell diamond, the center and the 8 cells at distance 2 share its color
+	even := func(pos Position) bool { return (pos.Row+pos.Column)%2 == 0 }
+	if count, err := calculator.CountCoveredWhere(grid, 2, even); err != nil || count != 9 {
+		t.Errorf("Expected 9 even cells, got %d (err=%v)", count, err)
+	}
+	if count, _ := calculator.CountCoveredWhere(grid, 2, nil); count != 13 {
+		t.Errorf("Expected nil predicate to count all 13, got %d", count)
+	}
+
+	// Overlapping sources do not double-count matches: row 3 spans columns 1-5 from (3,3)
+	// and 4-6 from (4,5)
+	grid, _ = NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	rowThree := func(pos Position) bool { return pos.Row == 3 }
+	if count, _ := calculator.CountCoveredWhere(grid, 2, rowThree); count != 6 {
+		t.Errorf("Expected 6 cells on row 3, got %d", count)
+	}
+
+	if _, err := calculator.CountCoveredWhere(grid, -1, even); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, err := calculator.CountCoveredWhere(nil, 2, even); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestCoverageEqual(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	cells := calculator.GetNeighborhoodCells(grid, 2)
+
+	// The same union through a different construction path
+	dynamic := make(map[Position]bool)
+	calculator.ForEachCell(grid, 2, func(pos Position, covered bool) {
+		dynamic[pos] = covered
+	})
+	if !CoverageEqual(cells, dynamic) || !CoverageEqual(dynamic, cells) {
+		t.Error("Expected sets differing only in false entries to be equal")
+	}
+	delete(dynamic, Position{Row: 3, Column: 3})
+	if CoverageEqual(cells, dynamic) {
+		t.Error("Expected a missing cell to make the sets unequal")
+	}
+	if !CoverageEqual(nil, map[Position]bool{{Row: 1, Column: 1}: false}) {
+		t.Error("Expected nil and all-false sets to be equal")
+	}
+}
+
+func TestCoverageEqualMatrix(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	cells := calculator.GetNeighborhoodCells(grid, 2)
+	matrix, _ := calculator.CoverageMatrix(grid, 2)
+
+	if !CoverageEqualMatrix(cells, matrix) {
+		t.Fatal("Expected the union to equal its CoverageMatrix")
+	}
+	matrix[0][0] = true
+	if CoverageEqualMatrix(cells, matrix) {
+		t.Error("Expected an extra matrix cell to make them unequal")
+	}
+	matrix[0][0] = false
+	cells[Position{Row: 20, Column: 0}] = true
+	if CoverageEqualMatrix(cells, matrix) {
+		t.Error("Expected a set cell outside the matrix to make them unequal")
+	}
+}
@@ -12,3 +12,21 @@ func NewDistanceCalculator() *DistanceCalculator {
 func (dc *DistanceCalculator) CalculateManhattanDistance(pos1, pos2 Position) int {
 	return pos1.ManhattanDistance(pos2)
 }
+
+// CalculateChamferDistance computes the chamfer distance between two positions, where an
+// orthogonal step costs orthogonalCost and a diagonal step costs diagonalCost.
+// A diagonalCost of at least 2*orthogonalCost never beats two orthogonal steps, so it
+// degenerates to a scaled Manhattan distance.
+func (dc *DistanceCalculator) CalculateChamferDistance(pos1, pos2 Position, orthogonalCost, diagonalCost int) int {
+	rowDiff := Abs(pos1.Row - pos2.Row)
+	colDiff := Abs(pos1.Column - pos2.Column)
+	diagonalSteps := min(rowDiff, colDiff)
+	straightSteps := max(rowDiff, colDiff) - diagonalSteps
+	return min(diagonalCost*diagonalSteps+orthogonalCost*straightSteps, orthogonalCost*(rowDiff+colDiff))
+}
+
+// CalculateWeightedManhattanDistance computes a Manhattan distance where each row step
+// costs verticalCost and each column step costs horizontalCost
+func (dc *DistanceCalculator) CalculateWeightedManhattanDistance(pos1, pos2 Position, verticalCost, horizontalCost int) int {
+	return verticalCost*Abs(pos1.Row-pos2.Row) + horizontalCost*Abs(pos1.Column-pos2.Column)
+}
@@ -0,0 +1,244 @@
+package gridneighborhoods
+
+import "slices"
+
+// MaxThresholdRange is the largest maxThreshold accepted by StreamCounts,
+// CountNeighborhoodCellsRange, and GrowthShells, which produce one entry per threshold.
+// Thresholds past grid.MaxManhattanDistance() only repeat the saturated result.
+const MaxThresholdRange = 1 << 20
+
+// checkThresholdRange validates a maxThreshold for the per-threshold methods
+func checkThresholdRange(maxThreshold int) error {
+	if maxThreshold < 0 {
+		return &InvalidDistanceThresholdError{Threshold: maxThreshold}
+	}
+	if maxThreshold > MaxThresholdRange {
+		return &ThresholdRangeTooLargeError{MaxThreshold: maxThreshold, Limit: MaxThresholdRange}
+	}
+	return nil
+}
+
+// forEachDistanceLayer visits the grid in rings of increasing Manhattan distance from the
+// nearest positive cell, using a multi-source breadth-first search. Layer 0 holds the
+// distinct positive cells; layer d holds every cell whose nearest source is exactly d away.
+// Iteration stops when fn returns false or every cell has been visited. Inside a
+// rectangle, and across the wrapped axes of the calculator's topology, BFS steps match
+// Manhattan distance exactly. The visited set only holds reached cells, so small
+// thresholds on huge grids stay cheap.
+func (nc *NeighborhoodCalculator) forEachDistanceLayer(grid *Grid, fn func(distance int, layer []Position) bool) {
+	visited := make(map[Position]bool, len(grid.PositiveCells))
+	layer := make([]Position, 0, len(grid.PositiveCells))
+	for _, pos := range grid.PositiveCells {
+		if !visited[pos] {
+			visited[pos] = true
+			layer = append(layer, pos)
+		}
+	}
+
+	for distance := 0; len(layer) > 0; distance++ {
+		if !fn(distance, layer) {
+			return
+		}
+		next := make([]Position, 0, len(layer)+4)
+		for _, pos := range layer {
+			for _, adjacent := range pos.Neighbors4() {
+				neighbor, ok := nc.wrapPosition(grid, adjacent)
+				if !ok {
+					continue
+				}
+				if !visited[neighbor] {
+					visited[neighbor] = true
+					next = append(next, neighbor)
+				}
+			}
+		}
+		layer = next
+	}
+}
+
+// countInUnion returns the number of positions in layer that can join the neighborhood
+// union: those neither blocked nor in excluded, the grid's excludedCells set
+func countInUnion(grid *Grid, excluded map[Position]bool, layer []Position) int {
+	count := 0
+	for _, pos := range layer {
+		if !grid.IsBlocked(pos) && !excluded[pos] {
+			count++
+		}
+	}
+	return count
+}
+
+// StreamCounts sends the neighborhood count for every threshold 0..maxThreshold to out,
+// in order, as soon as each one is known, then closes out. The channel is also closed
+// when an error is returned, in which case nothing is sent. A maxThreshold above
+// MaxThresholdRange yields a ThresholdRangeTooLargeError.
+func (nc *NeighborhoodCalculator) StreamCounts(grid *Grid, maxThreshold int, out chan<- int) error {
+	defer close(out)
+	if grid == nil {
+		return ErrNilGrid
+	}
+	if err := checkThresholdRange(maxThreshold); err != nil {
+		return err
+	}
+
+	excluded := grid.excludedCells()
+	count := 0
+	sent := 0
+	nc.forEachDistanceLayer(grid, func(distance int, layer []Position) bool {
+		if distance > maxThreshold {
+			return false
+		}
+		count += countInUnion(grid, excluded, layer)
+		out <- count
+		sent++
+		return true
+	})
+
+	// Every cell has been reached; larger thresholds add nothing
+	for ; sent <= maxThreshold; sent++ {
+		out <- count
+	}
+	return nil
+}
+
+// CountNeighborhoodCellsRange returns the neighborhood count for every threshold
+// 0..maxThreshold, computed in a single expanding pass. Element i equals
+// CountNeighborhoodCells(grid, i). maxThreshold is limited to MaxThresholdRange.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsRange(grid *Grid, maxThreshold int) ([]int, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if err := checkThresholdRange(maxThreshold); err != nil {
+		return nil, err
+	}
+
+	out := make(chan int)
+	go nc.StreamCounts(grid, maxThreshold, out)
+	counts := make([]int, 0, maxThreshold+1)
+	for count := range out {
+		counts = append(counts, count)
+	}
+	return counts, nil
+}
+
+// CountNeighborhoodCellsAt returns the neighborhood count for each requested threshold, in
+// input order, using a single expanding pass up to the largest threshold
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsAt(grid *Grid, thresholds []int) ([]int, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	requested := make(map[int]bool, len(thresholds))
+	maxThreshold := 0
+	for _, threshold := range thresholds {
+		if threshold < 0 {
+			return nil, &InvalidDistanceThresholdError{Threshold: threshold}
+		}
+		requested[threshold] = true
+		maxThreshold = max(maxThreshold, threshold)
+	}
+
+	excluded := grid.excludedCells()
+	countAt := make(map[int]int, len(requested))
+	count := 0
+	nc.forEachDistanceLayer(grid, func(distance int, layer []Position) bool {
+		if distance > maxThreshold {
+			return false
+		}
+		count += countInUnion(grid, excluded, layer)
+		if requested[distance] {
+			countAt[distance] = count
+		}
+		return true
+	})
+
+	counts := make([]int, len(thresholds))
+	for i, threshold := range thresholds {
+		if c, ok := countAt[threshold]; ok {
+			counts[i] = c
+		} else {
+			// The pass ended before this threshold, so nothing more was covered
+			counts[i] = count
+		}
+	}
+	return counts, nil
+}
+
+// MinThresholdForFullCoverage returns the smallest threshold whose neighborhood union
+// covers every unblocked, non-excluded cell, i.e. the largest distance from any such cell
+// to its nearest positive cell. Grids without positive cells yield a NoPositiveCellsError.
+func (nc *NeighborhoodCalculator) MinThresholdForFullCoverage(grid *Grid) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if !grid.HasPositiveCells() {
+		return 0, &NoPositiveCellsError{Height: grid.Height, Width: grid.Width}
+	}
+
+	excluded := grid.excludedCells()
+	threshold := 0
+	nc.forEachDistanceLayer(grid, func(distance int, layer []Position) bool {
+		if countInUnion(grid, excluded, layer) > 0 {
+			threshold = distance
+		}
+		return true
+	})
+	return threshold, nil
+}
+
+// GrowthShells returns, for every threshold 0..maxThreshold, the set of cells first covered
+// at that threshold; element 0 holds the positive cells. The shells are disjoint and their
+// union is GetNeighborhoodCells(grid, maxThreshold). Shells past full coverage are nil,
+// which reads as empty. It returns nil for a nil grid or a threshold that is negative or
+// above MaxThresholdRange.
+func (nc *NeighborhoodCalculator) GrowthShells(grid *Grid, maxThreshold int) []map[Position]bool {
+	if grid == nil || checkThresholdRange(maxThreshold) != nil {
+		return nil
+	}
+
+	excluded := grid.excludedCells()
+	shells := make([]map[Position]bool, maxThreshold+1)
+	nc.forEachDistanceLayer(grid, func(distance int, layer []Position) bool {
+		if distance > maxThreshold {
+			return false
+		}
+		shells[distance] = make(map[Position]bool, len(layer))
+		for _, pos := range layer {
+			if !grid.IsBlocked(pos) && !excluded[pos] {
+				shells[distance][pos] = true
+			}
+		}
+		return true
+	})
+	return shells
+}
+
+// CoverageByBand buckets cells by the distance to their nearest positive cell. bands holds
+// strictly increasing, non-negative inclusive upper edges: band 0 holds distances
+// 0..bands[0], band i holds bands[i-1]+1..bands[i], and the final band len(bands) holds
+// every farther cell. The result has an entry for each of the len(bands)+1 bands, and its
+// counts sum to the number of unblocked, non-excluded cells when any source exists.
+func (nc *NeighborhoodCalculator) CoverageByBand(grid *Grid, bands []int) (map[int]int, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	for i, edge := range bands {
+		if edge < 0 {
+			return nil, &InvalidDistanceThresholdError{Threshold: edge}
+		}
+		if i > 0 && edge <= bands[i-1] {
+			return nil, &InvalidBandEdgesError{Bands: slices.Clone(bands), Index: i}
+		}
+	}
+
+	counts := make(map[int]int, len(bands)+1)
+	for band := 0; band <= len(bands); band++ {
+		counts[band] = 0
+	}
+	excluded := grid.excludedCells()
+	nc.forEachDistanceLayer(grid, func(distance int, layer []Position) bool {
+		band, _ := slices.BinarySearch(bands, distance)
+		counts[band] += countInUnion(grid, excluded, layer)
+		return true
+	})
+	return counts, nil
+}
@@ -0,0 +1,300 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"maps"
+	"math"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestCountNeighborhoodCellsRangeMatchesCount(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		maxThreshold := rapid.IntRange(0, 45).Draw(t, "maxThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		counts, err := calculator.CountNeighborhoodCellsRange(grid, maxThreshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(counts) != maxThreshold+1 {
+			t.Fatalf("Expected %d counts, got %d", maxThreshold+1, len(counts))
+		}
+		for threshold, count := range counts {
+			expected, _ := calculator.CountNeighborhoodCells(grid, threshold)
+			if count != expected {
+				t.Fatalf("Threshold %d: expected %d, got %d", threshold, expected, count)
+			}
+		}
+	})
+}
+
+func TestStreamCountsDeliversInOrder(t *testing.T) {
+	// Scenario 1 geometry: diamond sizes 1, 5, 13, 25
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	out := make(chan int, 4)
+	if err := calculator.StreamCounts(grid, 3, out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []int{1, 5, 13, 25}
+	i := 0
+	for count := range out {
+		if i >= len(expected) || count != expected[i] {
+			t.Fatalf("Frame %d: unexpected count %d", i, count)
+		}
+		i++
+	}
+	if i != len(expected) {
+		t.Errorf("Expected %d frames, got %d", len(expected), i)
+	}
+
+	// Errors close the channel without sending
+	out = make(chan int, 1)
+	if err := calculator.StreamCounts(grid, -1, out); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, open := <-out; open {
+		t.Error("Expected closed channel after error")
+	}
+}
+
+func TestStreamCountsRespectsBlockedCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	grid.AddBlockedRect(6, 0, 6, 10)
+	calculator := NewNeighborhoodCalculator()
+
+	counts, _ := calculator.CountNeighborhoodCellsRange(grid, 25)
+	for threshold, count := range counts {
+		expected, _ := calculator.CountNeighborhoodCells(grid, threshold)
+		if count != expected {
+			t.Fatalf("Threshold %d: expected %d, got %d", threshold, expected, count)
+		}
+	}
+}
+
+func TestThresholdRangeLimits(t *testing.T) {
+	grid, _ := NewGrid(5, 5, []Position{{Row: 2, Column: 2}})
+	calculator := NewNeighborhoodCalculator()
+
+	var rangeErr *ThresholdRangeTooLargeError
+	if _, err := calculator.CountNeighborhoodCellsRange(grid, math.MaxInt); !errors.As(err, &rangeErr) || rangeErr.Limit != MaxThresholdRange {
+		t.Errorf("Expected ThresholdRangeTooLargeError, got %v", err)
+	}
+	out := make(chan int, 1)
+	if err := calculator.StreamCounts(grid, MaxThresholdRange+1, out); !errors.As(err, &rangeErr) {
+		t.Errorf("Expected ThresholdRangeTooLargeError from StreamCounts, got %v", err)
+	}
+	if _, open := <-out; open {
+		t.Error("Expected closed channel after error")
+	}
+	if shells := calculator.GrowthShells(grid, math.MaxInt); shells != nil {
+		t.Errorf("Expected nil shells, got %d", len(shells))
+	}
+
+	// Small thresholds on a huge grid only visit the reached cells
+	huge, _ := NewGrid(1<<15, 1<<15, []Position{{Row: 1 << 14, Column: 1 << 14}})
+	counts, err := calculator.CountNeighborhoodCellsRange(huge, 3)
+	if err != nil || !slices.Equal(counts, []int{1, 5, 13, 25}) {
+		t.Errorf("Expected [1 5 13 25], got %v (err=%v)", counts, err)
+	}
+}
+
+func TestCountNeighborhoodCellsAt(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// Unsorted, repeated, and saturating thresholds keep input order
+	counts, err := calculator.CountNeighborhoodCellsAt(grid, []int{15, 3, 1, 3, 0, 1 << 30})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []int{121, 25, 5, 25, 1, 121}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, counts)
+		}
+	}
+
+	if _, err := calculator.CountNeighborhoodCellsAt(grid, []int{1, -2}); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if counts, _ := calculator.CountNeighborhoodCellsAt(grid, nil); len(counts) != 0 {
+		t.Errorf("Expected no counts, got %v", counts)
+	}
+}
+
+func TestMinThresholdForFullCoverage(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 1: the center of an 11x11 grid is 10 steps from each corner
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	if threshold, err := calculator.MinThresholdForFullCoverage(grid); err != nil || threshold != 10 {
+		t.Errorf("Expected 10, got %d (err=%v)", threshold, err)
+	}
+
+	// Sources at opposite corners of a 5x5 grid leave the anti-diagonal 4 steps away
+	grid, _ = NewGrid(5, 5, []Position{{Row: 0, Column: 0}, {Row: 4, Column: 4}})
+	if threshold, err := calculator.MinThresholdForFullCoverage(grid); err != nil || threshold != 4 {
+		t.Errorf("Expected 4, got %d (err=%v)", threshold, err)
+	}
+
+	// Blocked cells need no coverage: without the far 2x2 corner the farthest cell is 6 away
+	grid, _ = NewGrid(5, 5, []Position{{Row: 0, Column: 0}})
+	grid.AddBlockedRect(3, 3, 4, 4)
+	if threshold, _ := calculator.MinThresholdForFullCoverage(grid); threshold != 6 {
+		t.Errorf("Expected 6 with far corner blocked, got %d", threshold)
+	}
+
+	empty, _ := NewGrid(5, 5, nil)
+	var noPositives *NoPositiveCellsError
+	if _, err := calculator.MinThresholdForFullCoverage(empty); !errors.As(err, &noPositives) {
+		t.Errorf("Expected NoPositiveCellsError, got %v", err)
+	}
+}
+
+func TestMinThresholdForFullCoverageIsMinimal(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		if !grid.HasPositiveCells() {
+			return
+		}
+		calculator := NewNeighborhoodCalculator()
+		threshold, err := calculator.MinThresholdForFullCoverage(grid)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		total := grid.CellCount() - grid.BlockedCellCount()
+		if count, _ := calculator.CountNeighborhoodCells(grid, threshold); count != total {
+			t.Fatalf("Threshold %d covers %d of %d cells", threshold, count, total)
+		}
+		if threshold > 0 {
+			if count, _ := calculator.CountNeighborhoodCells(grid, threshold-1); count == total {
+				t.Fatalf("Threshold %d already covers every cell", threshold-1)
+			}
+		}
+	})
+}
+
+func TestGrowthShells(t *testing.T) {
+	// Scenario 1: ring sizes 1, 4, 8, 12 around the center
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	shells := calculator.GrowthShells(grid, 3)
+	for i, expected := range []int{1, 4, 8, 12} {
+		if len(shells[i]) != expected {
+			t.Errorf("Shell %d: expected %d cells, got %d", i, expected, len(shells[i]))
+		}
+	}
+	if !shells[0][Position{Row: 5, Column: 5}] || !shells[3][Position{Row: 2, Column: 5}] {
+		t.Error("Expected the source in shell 0 and (2,5) in shell 3")
+	}
+
+	// Thresholds past full coverage add empty shells
+	if shells := calculator.GrowthShells(grid, 12); len(shells) != 13 || len(shells[11]) != 0 || len(shells[12]) != 0 {
+		t.Errorf("Expected 13 shells ending empty, got %d", len(shells))
+	}
+	if calculator.GrowthShells(grid, -1) != nil || calculator.GrowthShells(nil, 3) != nil {
+		t.Error("Expected nil for invalid input")
+	}
+}
+
+func TestGrowthShellsPartitionUnion(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		maxThreshold := rapid.IntRange(0, 20).Draw(t, "maxThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		union := calculator.GetNeighborhoodCells(grid, maxThreshold)
+		seen := make(map[Position]bool)
+		for i, shell := range calculator.GrowthShells(grid, maxThreshold) {
+			for pos := range shell {
+				if seen[pos] {
+					t.Fatalf("Cell %v appears in more than one shell", pos)
+				}
+				if !union[pos] {
+					t.Fatalf("Shell %d holds uncovered cell %v", i, pos)
+				}
+				seen[pos] = true
+			}
+		}
+		if len(seen) != len(union) {
+			t.Fatalf("Shells cover %d cells, union has %d", len(seen), len(union))
+		}
+	})
+}
+
+func TestCoverageByBand(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// Bands 0-3, 4-7, and 8+: 25 cells within 3; 24 cells past 7 fill the four corners
+	bands, err := calculator.CoverageByBand(grid, []int{3, 7})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	within7, _ := calculator.CountNeighborhoodCells(grid, 7)
+	expected := map[int]int{0: 25, 1: within7 - 25, 2: 121 - within7}
+	if !maps.Equal(bands, expected) || bands[2] != 24 {
+		t.Errorf("Expected %v, got %v", expected, bands)
+	}
+
+	// Empty bands are still reported
+	bands, _ = calculator.CoverageByBand(grid, []int{0, 100, 200})
+	if !maps.Equal(bands, map[int]int{0: 1, 1: 120, 2: 0, 3: 0}) {
+		t.Errorf("Unexpected bands %v", bands)
+	}
+
+	var edgesErr *InvalidBandEdgesError
+	if _, err := calculator.CoverageByBand(grid, []int{3, 3}); !errors.As(err, &edgesErr) || edgesErr.Index != 1 {
+		t.Errorf("Expected InvalidBandEdgesError at index 1, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CoverageByBand(grid, []int{-1, 3}); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+	if _, err := calculator.CoverageByBand(nil, []int{3}); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestCoverageByBandMatchesCounts(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		first := rapid.IntRange(0, 10).Draw(t, "first")
+		second := first + rapid.IntRange(1, 10).Draw(t, "gap")
+		calculator := NewNeighborhoodCalculator()
+
+		bands, _ := calculator.CoverageByBand(grid, []int{first, second})
+		countFirst, _ := calculator.CountNeighborhoodCells(grid, first)
+		countSecond, _ := calculator.CountNeighborhoodCells(grid, second)
+		countAll, _ := calculator.CountNeighborhoodCells(grid, grid.MaxManhattanDistance())
+		if bands[0] != countFirst || bands[1] != countSecond-countFirst || bands[2] != countAll-countSecond {
+			t.Fatalf("Bands %v disagree with counts %d, %d, %d", bands, countFirst, countSecond, countAll)
+		}
+	})
+}
+
+func TestDistanceLayersHonorExcludedCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	grid.Excluded = []Position{{Row: 5, Column: 6}, {Row: 0, Column: 0}, {Row: 0, Column: 10}, {Row: 10, Column: 0}, {Row: 10, Column: 10}}
+	calculator := NewNeighborhoodCalculator()
+
+	counts, _ := calculator.CountNeighborhoodCellsRange(grid, 10)
+	for threshold, count := range counts {
+		if expected, _ := calculator.CountNeighborhoodCells(grid, threshold); count != expected {
+			t.Errorf("Threshold %d: expected %d, got %d", threshold, expected, count)
+		}
+	}
+	// The excluded far corners no longer need covering
+	if threshold, _ := calculator.MinThresholdForFullCoverage(grid); threshold != 9 {
+		t.Errorf("Expected 9, got %d", threshold)
+	}
+}
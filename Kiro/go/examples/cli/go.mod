@@ -0,0 +1,7 @@
+module grid-neighborhoods
+
+go 1.25
+
+require gridneighborhoods v0.0.0
+
+replace gridneighborhoods => ../..
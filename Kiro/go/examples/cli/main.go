@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gridneighborhoods"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes for the typed library errors, so scripts can branch on the failure kind
+const (
+	exitOK                = 0
+	exitFailure           = 1
+	exitUsage             = 2
+	exitInvalidDimensions = 3
+	exitOutOfBounds       = 4
+	exitInvalidThreshold  = 5
+	exitMalformedInput    = 6
+)
+
+// positionList collects repeated -cell flags given as "row,column"
+type positionList []gridneighborhoods.Position
+
+func (p *positionList) String() string {
+	parts := make([]string, len(*p))
+	for i, pos := range *p {
+		text, _ := pos.MarshalText()
+		parts[i] = string(text)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *positionList) Set(value string) error {
+	var pos gridneighborhoods.Position
+	if err := pos.UnmarshalText([]byte(value)); err != nil {
+		return err
+	}
+	*p = append(*p, pos)
+	return nil
+}
+
+// Command-line program that counts neighborhood cells for a grid read from flags or stdin.
+//
+//	printf '11x11\n5 5\n' | grid-neighborhoods -n 3
+//	grid-neighborhoods -dims 11x11 -cell 3,3 -cell 4,5 -n 2
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses arguments, computes the count, and returns the process exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("grid-neighborhoods", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	threshold := flags.Int("n", -1, "distance threshold (required, >= 0)")
+	dims := flags.String("dims", "", "grid dimensions as HxW; when omitted the grid is read from stdin")
+	var cells positionList
+	flags.Var(&cells, "cell", "positive cell as row,column (repeatable, requires -dims)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if !isFlagSet(flags, "n") {
+		fmt.Fprintln(stderr, "error: -n is required")
+		flags.Usage()
+		return exitUsage
+	}
+
+	var grid *gridneighborhoods.Grid
+	var err error
+	if *dims != "" {
+		grid, err = gridneighborhoods.LoadGrid(strings.NewReader(*dims + "\n" + cellLines(cells)))
+	} else {
+		if len(cells) > 0 {
+			fmt.Fprintln(stderr, "error: -cell requires -dims")
+			return exitUsage
+		}
+		grid, err = gridneighborhoods.LoadGrid(stdin)
+	}
+	if err != nil {
+		return fail(stderr, err)
+	}
+
+	count, err := gridneighborhoods.NewNeighborhoodCalculator().CountNeighborhoodCells(grid, *threshold)
+	if err != nil {
+		return fail(stderr, err)
+	}
+	fmt.Fprintln(stdout, count)
+	return exitOK
+}
+
+// cellLines renders positions in the LoadGrid "row col" line format
+func cellLines(cells positionList) string {
+	var b strings.Builder
+	for _, pos := range cells {
+		fmt.Fprintf(&b, "%d %d\n", pos.Row, pos.Column)
+	}
+	return b.String()
+}
+
+// isFlagSet reports whether a flag was given explicitly
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// fail prints err and maps it to an exit code
+func fail(stderr io.Writer, err error) int {
+	fmt.Fprintf(stderr, "error: %v\n", err)
+
+	var dimensionsErr *gridneighborhoods.InvalidGridDimensionsError
+	var tooLargeErr *gridneighborhoods.GridTooLargeError
+	var boundsErr *gridneighborhoods.PositionOutOfBoundsError
+	var thresholdErr *gridneighborhoods.InvalidDistanceThresholdError
+	var formatErr *gridneighborhoods.GridFormatError
+	switch {
+	case errors.As(err, &dimensionsErr), errors.As(err, &tooLargeErr):
+		return exitInvalidDimensions
+	case errors.As(err, &boundsErr):
+		return exitOutOfBounds
+	case errors.As(err, &thresholdErr):
+		return exitInvalidThreshold
+	case errors.As(err, &formatErr):
+		return exitMalformedInput
+	default:
+		return exitFailure
+	}
+}
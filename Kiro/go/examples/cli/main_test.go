@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunExitCodes(t *testing.T) {
+	cases := []struct {
+		args   []string
+		stdin  string
+		code   int
+		stdout string
+	}{
+		{[]string{"-n", "3"}, "11x11\n5 5\n", exitOK, "25\n"},
+		{[]string{"-dims", "11x11", "-cell", "3,3", "-cell", "4,5", "-n", "2"}, "", exitOK, "22\n"},
+		{[]string{"-n", "3"}, "0x11\n", exitInvalidDimensions, ""},
+		{[]string{"-n", "3"}, "11x11\n11 0\n", exitOutOfBounds, ""},
+		{[]string{"-n", "-1"}, "11x11\n5 5\n", exitInvalidThreshold, ""},
+		{[]string{"-n", "3"}, "11x11\nfive five\n", exitMalformedInput, ""},
+		{[]string{}, "11x11\n", exitUsage, ""},
+		{[]string{"-cell", "1,1", "-n", "1"}, "", exitUsage, ""},
+	}
+
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+		if code != tc.code {
+			t.Errorf("Args %v: expected exit code %d, got %d (stderr=%q)", tc.args, tc.code, code, stderr.String())
+		}
+		if stdout.String() != tc.stdout {
+			t.Errorf("Args %v: expected stdout %q, got %q", tc.args, tc.stdout, stdout.String())
+		}
+	}
+}
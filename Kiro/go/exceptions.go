@@ -1,6 +1,12 @@
 package gridneighborhoods
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNilGrid is returned when a calculator method is given a nil grid
+var ErrNilGrid = errors.New("grid is nil")
 
 // InvalidGridDimensionsError represents an error when grid dimensions are invalid
 type InvalidGridDimensionsError struct {
@@ -23,11 +29,267 @@ func (e *PositionOutOfBoundsError) Error() string {
 	return fmt.Sprintf("position (%d,%d) is out of bounds for grid %dx%d", e.Position.Row, e.Position.Column, e.Height, e.Width)
 }
 
-// InvalidDistanceThresholdError represents an error when distance threshold is negative
+// InvalidDistanceThresholdError represents an error when distance threshold is negative.
+// Center is set when the threshold belongs to a specific source.
 type InvalidDistanceThresholdError struct {
 	Threshold int
+	Center    *Position
 }
 
 func (e *InvalidDistanceThresholdError) Error() string {
+	if e.Center != nil {
+		return fmt.Sprintf("invalid distance threshold: %d for center (%d,%d) (must be >= 0)", e.Threshold, e.Center.Row, e.Center.Column)
+	}
 	return fmt.Sprintf("invalid distance threshold: %d (must be >= 0)", e.Threshold)
 }
+
+// GridFormatError represents a malformed line in the text grid format
+type GridFormatError struct {
+	Line   int
+	Reason string
+	Err    error
+}
+
+func (e *GridFormatError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Unwrap returns the underlying validation error, if any
+func (e *GridFormatError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidRectangleError represents an error when a rectangle's minimum corner exceeds its maximum corner
+type InvalidRectangleError struct {
+	MinRow int
+	MinCol int
+	MaxRow int
+	MaxCol int
+}
+
+func (e *InvalidRectangleError) Error() string {
+	return fmt.Sprintf("invalid rectangle: (%d,%d)-(%d,%d) (min must be <= max)", e.MinRow, e.MinCol, e.MaxRow, e.MaxCol)
+}
+
+// BlockedPositiveCellError represents an error when a positive cell falls inside a blocked region
+type BlockedPositiveCellError struct {
+	Position Position
+}
+
+func (e *BlockedPositiveCellError) Error() string {
+	return fmt.Sprintf("positive cell (%d,%d) lies inside a blocked region", e.Position.Row, e.Position.Column)
+}
+
+// InvalidMoveCostError represents an error when a per-step movement cost is not positive
+type InvalidMoveCostError struct {
+	Name string
+	Cost int
+}
+
+func (e *InvalidMoveCostError) Error() string {
+	return fmt.Sprintf("invalid %s move cost: %d (must be > 0)", e.Name, e.Cost)
+}
+
+// PositionFormatError represents an error when text cannot be parsed as a position
+type PositionFormatError struct {
+	Text   string
+	Reason string
+}
+
+func (e *PositionFormatError) Error() string {
+	return fmt.Sprintf("invalid position %q: %s", e.Text, e.Reason)
+}
+
+// InvalidGrid3DDimensionsError represents an error when 3D grid dimensions are invalid
+type InvalidGrid3DDimensionsError struct {
+	Height int
+	Width  int
+	Depth  int
+}
+
+func (e *InvalidGrid3DDimensionsError) Error() string {
+	return fmt.Sprintf("invalid grid dimensions: height=%d, width=%d, depth=%d (all must be > 0)", e.Height, e.Width, e.Depth)
+}
+
+// Grid3DTooLargeError represents an error when height*width*depth overflows int
+type Grid3DTooLargeError struct {
+	Height int
+	Width  int
+	Depth  int
+}
+
+func (e *Grid3DTooLargeError) Error() string {
+	return fmt.Sprintf("grid %dx%dx%d has more cells than an int can count", e.Height, e.Width, e.Depth)
+}
+
+// Position3DOutOfBoundsError represents an error when a position is outside 3D grid boundaries
+type Position3DOutOfBoundsError struct {
+	Position Position3D
+	Height   int
+	Width    int
+	Depth    int
+}
+
+func (e *Position3DOutOfBoundsError) Error() string {
+	return fmt.Sprintf("position (%d,%d,%d) is out of bounds for grid %dx%dx%d", e.Position.Row, e.Position.Column, e.Position.Layer, e.Height, e.Width, e.Depth)
+}
+
+// ResultTooLargeError represents an error when a neighborhood union exceeds the configured cell limit
+type ResultTooLargeError struct {
+	Limit int
+}
+
+func (e *ResultTooLargeError) Error() string {
+	return fmt.Sprintf("neighborhood union exceeds the limit of %d cells", e.Limit)
+}
+
+// ThresholdRangeTooLargeError represents an error when a per-threshold result would need
+// more entries than MaxThresholdRange allows
+type ThresholdRangeTooLargeError struct {
+	MaxThreshold int
+	Limit        int
+}
+
+func (e *ThresholdRangeTooLargeError) Error() string {
+	return fmt.Sprintf("threshold range 0..%d exceeds the limit of %d thresholds", e.MaxThreshold, e.Limit)
+}
+
+// NoPositiveCellsError represents an error when a grid has no positive cells to count from
+type NoPositiveCellsError struct {
+	Height int
+	Width  int
+}
+
+func (e *NoPositiveCellsError) Error() string {
+	return fmt.Sprintf("grid %dx%d has no positive cells", e.Height, e.Width)
+}
+
+// GridTooLargeError represents an error when height*width exceeds the configured cell limit
+type GridTooLargeError struct {
+	Height int
+	Width  int
+	Limit  int
+}
+
+func (e *GridTooLargeError) Error() string {
+	return fmt.Sprintf("grid %dx%d exceeds the limit of %d cells", e.Height, e.Width, e.Limit)
+}
+
+// RaggedMatrixError represents an error when a matrix row's length differs from the first row's
+type RaggedMatrixError struct {
+	Row      int
+	Expected int
+	Got      int
+}
+
+func (e *RaggedMatrixError) Error() string {
+	return fmt.Sprintf("matrix row %d has %d columns, expected %d", e.Row, e.Got, e.Expected)
+}
+
+// InvalidRadiusError represents an error when a float radius has no integer threshold
+type InvalidRadiusError struct {
+	Radius float64
+}
+
+func (e *InvalidRadiusError) Error() string {
+	return fmt.Sprintf("radius %v cannot be converted to a distance threshold", e.Radius)
+}
+
+// InvalidRoundModeError represents an error when a RoundMode is not Floor, Round, or Ceil
+type InvalidRoundModeError struct {
+	Mode RoundMode
+}
+
+func (e *InvalidRoundModeError) Error() string {
+	return fmt.Sprintf("invalid round mode %d", int(e.Mode))
+}
+
+// InvalidPlacementCountError represents an error when a negative number of sources is requested
+type InvalidPlacementCountError struct {
+	K int
+}
+
+func (e *InvalidPlacementCountError) Error() string {
+	return fmt.Sprintf("cannot place %d sources", e.K)
+}
+
+// SourceNotFoundError represents an error when no source exists at the given position
+type SourceNotFoundError struct {
+	Position Position
+}
+
+func (e *SourceNotFoundError) Error() string {
+	return fmt.Sprintf("no source at (%d,%d)", e.Position.Row, e.Position.Column)
+}
+
+// DuplicatePositionError represents an error when a positive cell is listed more than once
+type DuplicatePositionError struct {
+	Position Position
+	Count    int
+}
+
+func (e *DuplicatePositionError) Error() string {
+	return fmt.Sprintf("positive cell (%d,%d) appears %d times", e.Position.Row, e.Position.Column, e.Count)
+}
+
+// InvalidBlockSizeError represents an error when a downsampling block is not at least 1x1
+type InvalidBlockSizeError struct {
+	Rows    int
+	Columns int
+}
+
+func (e *InvalidBlockSizeError) Error() string {
+	return fmt.Sprintf("invalid block size %dx%d: both dimensions must be positive", e.Rows, e.Columns)
+}
+
+// InvalidTimeWindowError represents an error when a source's active window ends before it starts
+type InvalidTimeWindowError struct {
+	Position Position
+	Start    int
+	End      int
+}
+
+func (e *InvalidTimeWindowError) Error() string {
+	return fmt.Sprintf("source (%d,%d) has invalid time window [%d,%d)", e.Position.Row, e.Position.Column, e.Start, e.End)
+}
+
+// InvalidSampleFractionError represents an error when a sample fraction is outside (0,1]
+type InvalidSampleFractionError struct {
+	Fraction float64
+}
+
+func (e *InvalidSampleFractionError) Error() string {
+	return fmt.Sprintf("sample fraction %v must be in (0,1]", e.Fraction)
+}
+
+// TileCountMismatchError represents an error when tiles and their coverage sets differ in number
+type TileCountMismatchError struct {
+	Tiles     int
+	Coverages int
+}
+
+func (e *TileCountMismatchError) Error() string {
+	return fmt.Sprintf("got %d coverage sets for %d tiles", e.Coverages, e.Tiles)
+}
+
+// InvalidBandEdgesError represents an error when distance band edges are not strictly increasing
+type InvalidBandEdgesError struct {
+	Bands []int
+	Index int
+}
+
+func (e *InvalidBandEdgesError) Error() string {
+	return fmt.Sprintf("band edges %v must be strictly increasing: edge %d is %d", e.Bands, e.Index, e.Bands[e.Index])
+}
+
+// NegativePositionError represents an error when a position has a negative row or column
+type NegativePositionError struct {
+	Position Position
+}
+
+func (e *NegativePositionError) Error() string {
+	return fmt.Sprintf("position (%d,%d) has a negative coordinate", e.Position.Row, e.Position.Column)
+}
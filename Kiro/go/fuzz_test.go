@@ -0,0 +1,89 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+// fuzzPositions decodes consecutive byte pairs as (row, column) offsets. Offsets are shifted
+// slightly negative so out-of-bounds positions are exercised too.
+func fuzzPositions(data []byte) []Position {
+	positions := make([]Position, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		positions = append(positions, Position{Row: int(data[i]) - 8, Column: int(data[i+1]) - 8})
+	}
+	return positions
+}
+
+func FuzzNewGrid(f *testing.F) {
+	f.Add(11, 11, []byte{13, 13})
+	f.Add(0, 5, []byte{})
+	f.Add(-1, 1<<20, []byte{8, 8, 9, 9})
+	f.Add(math.MaxInt, 2, []byte{})
+
+	f.Fuzz(func(t *testing.T, height, width int, data []byte) {
+		positions := fuzzPositions(data)
+		grid, err := NewGrid(height, width, positions)
+		if err != nil {
+			var dimensionsErr *InvalidGridDimensionsError
+			var boundsErr *PositionOutOfBoundsError
+			var tooLargeErr *GridTooLargeError
+			if !errors.As(err, &dimensionsErr) && !errors.As(err, &boundsErr) && !errors.As(err, &tooLargeErr) {
+				t.Fatalf("Unexpected error type %T: %v", err, err)
+			}
+			return
+		}
+		if grid.Height != height || grid.Width != width || height <= 0 || width <= 0 {
+			t.Fatalf("Accepted invalid dimensions %dx%d", height, width)
+		}
+		for _, pos := range grid.PositiveCells {
+			if !grid.IsValidPosition(pos) {
+				t.Fatalf("Accepted out-of-bounds position %v", pos)
+			}
+		}
+	})
+}
+
+func FuzzCount(f *testing.F) {
+	f.Add(uint8(11), uint8(11), []byte{13, 13}, 3)
+	f.Add(uint8(11), uint8(11), []byte{11, 11, 12, 13}, 2)
+	f.Add(uint8(1), uint8(1), []byte{8, 8}, 0)
+	f.Add(uint8(40), uint8(3), []byte{}, -1)
+
+	f.Fuzz(func(t *testing.T, height, width uint8, data []byte, distanceThreshold int) {
+		var inBounds []Position
+		for _, pos := range fuzzPositions(data) {
+			if pos.Row >= 0 && pos.Row < int(height) && pos.Column >= 0 && pos.Column < int(width) {
+				inBounds = append(inBounds, pos)
+			}
+		}
+		grid, err := NewGrid(int(height), int(width), inBounds)
+		if err != nil {
+			return
+		}
+
+		count, err := NewNeighborhoodCalculator().CountNeighborhoodCells(grid, distanceThreshold)
+		if distanceThreshold < 0 {
+			if err == nil {
+				t.Fatalf("Expected an error for threshold %d", distanceThreshold)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count < 0 || count > grid.CellCount() {
+			t.Fatalf("Count %d outside [0, %d]", count, grid.CellCount())
+		}
+		distinct := make(map[Position]bool)
+		for _, pos := range grid.PositiveCells {
+			distinct[pos] = true
+		}
+		if count < len(distinct) {
+			t.Fatalf("Count %d smaller than %d distinct positive cells", count, len(distinct))
+		}
+	})
+}
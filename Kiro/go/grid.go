@@ -1,19 +1,49 @@
 package gridneighborhoods
 
+import (
+	"maps"
+	"math"
+	"slices"
+)
+
 // Grid represents a 2D grid with positive cell positions
 type Grid struct {
 	Height        int
 	Width         int
 	PositiveCells []Position
+
+	// Origin selects where row 0 is drawn by RenderASCII and RenderPNG; counts ignore it
+	Origin CoordinateSystem
+
+	// Excluded lists cells dropped from every neighborhood union, such as the one counted
+	// by CountNeighborhoodCells and returned by GetNeighborhoodCells, and from per-cell
+	// coverage maps like CoverageMultiplicity. Unlike blocked cells they are still
+	// enumerated by per-source queries such as CountSingleNeighborhood and
+	// PerSourceNeighborhoods. Positions outside the grid are ignored.
+	Excluded []Position
+
+	// blocked holds cells masked by AddBlockedRect
+	blocked map[Position]bool
+
+	// positive is the set of PositiveCells built at construction, backing IsPositive
+	positive map[Position]bool
 }
 
-// NewGrid creates a new grid with validation
+// NewGrid creates a new grid with validation. The grid stores its own copy of
+// positiveCells, so later changes to the caller's slice do not affect it. Dimensions whose
+// product overflows int are rejected with a GridTooLargeError; the tighter limit on dense
+// outputs is set per calculator with WithMaxGridCells.
 func NewGrid(height, width int, positiveCells []Position) (*Grid, error) {
 	// Validate dimensions
 	if height <= 0 || width <= 0 {
 		return nil, &InvalidGridDimensionsError{Height: height, Width: width}
 	}
 
+	// Validate the cell count without computing an overflowing product
+	if height > math.MaxInt/width {
+		return nil, &GridTooLargeError{Height: height, Width: width, Limit: math.MaxInt}
+	}
+
 	// Validate all positive cell positions are within bounds
 	for _, pos := range positiveCells {
 		if pos.Row < 0 || pos.Row >= height || pos.Column < 0 || pos.Column >= width {
@@ -24,10 +54,197 @@ func NewGrid(height, width int, positiveCells []Position) (*Grid, error) {
 	return &Grid{
 		Height:        height,
 		Width:         width,
-		PositiveCells: positiveCells,
+		PositiveCells: slices.Clone(positiveCells),
+		positive:      positiveSet(positiveCells),
 	}, nil
 }
 
+// positiveSet returns the set of distinct positions in cells
+func positiveSet(cells []Position) map[Position]bool {
+	set := make(map[Position]bool, len(cells))
+	for _, pos := range cells {
+		set[pos] = true
+	}
+	return set
+}
+
+// IsPositive reports whether pos is one of the grid's positive cells in O(1), using the
+// set built at construction. Grids assembled without a constructor fall back to a linear
+// scan of PositiveCells.
+func (g *Grid) IsPositive(pos Position) bool {
+	if g.positive == nil {
+		return slices.Contains(g.PositiveCells, pos)
+	}
+	return g.positive[pos]
+}
+
+// SetPositiveCells replaces the grid's positive cells with a deduplicated copy of cells,
+// keeping the first occurrence of each, and rebuilds the set behind IsPositive. Positions
+// outside the grid or blocked are rejected and leave the grid unchanged.
+func (g *Grid) SetPositiveCells(cells []Position) error {
+	set := make(map[Position]bool, len(cells))
+	unique := make([]Position, 0, len(cells))
+	for _, pos := range cells {
+		if !g.IsValidPosition(pos) {
+			return &PositionOutOfBoundsError{Position: pos, Height: g.Height, Width: g.Width}
+		}
+		if g.IsBlocked(pos) {
+			return &BlockedPositiveCellError{Position: pos}
+		}
+		if !set[pos] {
+			set[pos] = true
+			unique = append(unique, pos)
+		}
+	}
+	g.PositiveCells = unique
+	g.positive = set
+	return nil
+}
+
+// AppendRow returns a copy of the grid with one more row. The new row takes index Height,
+// so existing positive, blocked, and excluded cells keep their positions. The receiver is
+// unchanged.
+func (g *Grid) AppendRow() *Grid {
+	return g.resized(g.Height+1, g.Width)
+}
+
+// AppendColumn returns a copy of the grid with one more column at index Width, keeping
+// existing cells in place like AppendRow
+func (g *Grid) AppendColumn() *Grid {
+	return g.resized(g.Height, g.Width+1)
+}
+
+// resized copies the grid with new dimensions that are at least the current ones
+func (g *Grid) resized(height, width int) *Grid {
+	return &Grid{
+		Height:        height,
+		Width:         width,
+		PositiveCells: slices.Clone(g.PositiveCells),
+		Origin:        g.Origin,
+		Excluded:      slices.Clone(g.Excluded),
+		blocked:       maps.Clone(g.blocked),
+		positive:      maps.Clone(g.positive),
+	}
+}
+
+// NewGridBetween creates the grid spanning the inclusive bounding box of corners a and b,
+// in either order. positiveCells use the same coordinates as the corners and are shifted so
+// the minimum corner becomes (0,0); validation errors name the shifted positions.
+func NewGridBetween(a, b Position, positiveCells []Position) (*Grid, error) {
+	minCorner := Position{Row: min(a.Row, b.Row), Column: min(a.Column, b.Column)}
+	maxCorner := Position{Row: max(a.Row, b.Row), Column: max(a.Column, b.Column)}
+	extent := maxCorner.Sub(minCorner)
+
+	shifted := make([]Position, len(positiveCells))
+	for i, pos := range positiveCells {
+		shifted[i] = pos.Sub(minCorner)
+	}
+	// Corners far enough apart overflow the extent, which NewGrid rejects as non-positive
+	return NewGrid(extent.Row+1, extent.Column+1, shifted)
+}
+
+// NewGridSorted creates a new grid like NewGrid, storing a copy of the positive cells
+// sorted by row, then column, so iteration over PositiveCells is deterministic
+func NewGridSorted(height, width int, positiveCells []Position) (*Grid, error) {
+	grid, err := NewGrid(height, width, positiveCells)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(grid.PositiveCells, comparePositions)
+	return grid, nil
+}
+
+// NewGridStrict creates a new grid like NewGrid but rejects repeated positive cells. The
+// returned DuplicatePositionError names the first position, in input order, that appears
+// more than once, together with its total number of occurrences.
+func NewGridStrict(height, width int, positiveCells []Position) (*Grid, error) {
+	grid, err := NewGrid(height, width, positiveCells)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := make(map[Position]int, len(positiveCells))
+	for _, pos := range positiveCells {
+		occurrences[pos]++
+	}
+	for _, pos := range positiveCells {
+		if occurrences[pos] > 1 {
+			return nil, &DuplicatePositionError{Position: pos, Count: occurrences[pos]}
+		}
+	}
+	return grid, nil
+}
+
+// NewGridFromMatrix creates a grid whose dimensions come from the matrix and whose
+// positive cells are the entries equal to positiveMarker. Entry m[row][col] maps to
+// Position{Row: row, Column: col}, the same layout CoverageMatrix produces.
+func NewGridFromMatrix(m [][]byte, positiveMarker byte) (*Grid, error) {
+	if len(m) == 0 {
+		return nil, &InvalidGridDimensionsError{Height: 0, Width: 0}
+	}
+
+	width := len(m[0])
+	var positiveCells []Position
+	for row, cells := range m {
+		if len(cells) != width {
+			return nil, &RaggedMatrixError{Row: row, Expected: width, Got: len(cells)}
+		}
+		for col, cell := range cells {
+			if cell == positiveMarker {
+				positiveCells = append(positiveCells, Position{Row: row, Column: col})
+			}
+		}
+	}
+	return NewGrid(len(m), width, positiveCells)
+}
+
+// Downsample aggregates the grid into blockRows x blockCols super-cells and returns the
+// coarse grid, where a super-cell is positive if its block holds any positive cell. A
+// dimension that is not a multiple of the block size gains a partial final block. The
+// positive cells are sorted by row, then column; the blocked mask is not carried over.
+func Downsample(grid *Grid, blockRows, blockCols int) (*Grid, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if blockRows <= 0 || blockCols <= 0 {
+		return nil, &InvalidBlockSizeError{Rows: blockRows, Columns: blockCols}
+	}
+
+	coarse := make(map[Position]bool, len(grid.PositiveCells))
+	for _, pos := range grid.PositiveCells {
+		coarse[Position{Row: pos.Row / blockRows, Column: pos.Column / blockCols}] = true
+	}
+	downsampled, err := NewGrid((grid.Height+blockRows-1)/blockRows, (grid.Width+blockCols-1)/blockCols, sortedPositions(coarse))
+	if err != nil {
+		return nil, err
+	}
+	downsampled.Origin = grid.Origin
+	return downsampled, nil
+}
+
+// HasPositiveCells reports whether the grid has at least one positive cell
+func (g *Grid) HasPositiveCells() bool {
+	return len(g.PositiveCells) > 0
+}
+
+// CellCount returns Height*Width, saturating at math.MaxInt instead of overflowing.
+// Grids with a non-positive dimension have no cells.
+func (g *Grid) CellCount() int {
+	if g.Height <= 0 || g.Width <= 0 {
+		return 0
+	}
+	if g.Height > math.MaxInt/g.Width {
+		return math.MaxInt
+	}
+	return g.Height * g.Width
+}
+
+// MaxManhattanDistance returns the largest Manhattan distance between any two grid cells,
+// i.e. between opposite corners. A threshold at least this large covers the whole grid.
+func (g *Grid) MaxManhattanDistance() int {
+	return (g.Height - 1) + (g.Width - 1)
+}
+
 // IsValidPosition checks if a position is within grid boundaries
 func (g *Grid) IsValidPosition(pos Position) bool {
 	return pos.Row >= 0 && pos.Row < g.Height && pos.Column >= 0 && pos.Column < g.Width
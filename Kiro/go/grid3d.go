@@ -0,0 +1,105 @@
+package gridneighborhoods
+
+import (
+	"math"
+	"slices"
+)
+
+// Position3D represents a voxel position in a 3D grid
+type Position3D struct {
+	Row    int
+	Column int
+	Layer  int
+}
+
+// ManhattanDistance calculates the 3D Manhattan distance between two positions
+func (p Position3D) ManhattanDistance(other Position3D) int {
+	return Abs(p.Row-other.Row) + Abs(p.Column-other.Column) + Abs(p.Layer-other.Layer)
+}
+
+// Grid3D represents a 3D voxel grid with positive cell positions
+type Grid3D struct {
+	Height        int
+	Width         int
+	Depth         int
+	PositiveCells []Position3D
+}
+
+// NewGrid3D creates a new 3D grid with validation. Like NewGrid, it rejects dimensions
+// whose cell count overflows int and stores its own copy of positiveCells.
+func NewGrid3D(height, width, depth int, positiveCells []Position3D) (*Grid3D, error) {
+	// Validate dimensions
+	if height <= 0 || width <= 0 || depth <= 0 {
+		return nil, &InvalidGrid3DDimensionsError{Height: height, Width: width, Depth: depth}
+	}
+
+	// Validate the cell count without computing an overflowing product
+	if height > math.MaxInt/width || height*width > math.MaxInt/depth {
+		return nil, &Grid3DTooLargeError{Height: height, Width: width, Depth: depth}
+	}
+
+	grid := &Grid3D{Height: height, Width: width, Depth: depth}
+
+	// Validate all positive cell positions are within bounds
+	for _, pos := range positiveCells {
+		if !grid.IsValidPosition(pos) {
+			return nil, &Position3DOutOfBoundsError{Position: pos, Height: height, Width: width, Depth: depth}
+		}
+	}
+
+	grid.PositiveCells = slices.Clone(positiveCells)
+	return grid, nil
+}
+
+// IsValidPosition checks if a position is within grid boundaries
+func (g *Grid3D) IsValidPosition(pos Position3D) bool {
+	return pos.Row >= 0 && pos.Row < g.Height &&
+		pos.Column >= 0 && pos.Column < g.Width &&
+		pos.Layer >= 0 && pos.Layer < g.Depth
+}
+
+// MaxManhattanDistance returns the largest Manhattan distance between any two voxels
+func (g *Grid3D) MaxManhattanDistance() int {
+	return (g.Height - 1) + (g.Width - 1) + (g.Depth - 1)
+}
+
+// Count3DNeighborhoodCells counts the total unique voxels in all octahedral neighborhoods
+func (nc *NeighborhoodCalculator) Count3DNeighborhoodCells(grid *Grid3D, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	// Validate distance threshold
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	// Handle empty positive cells case
+	if len(grid.PositiveCells) == 0 {
+		return 0, nil
+	}
+
+	// Early termination: the threshold reaches every voxel from any source
+	if distanceThreshold >= grid.MaxManhattanDistance() {
+		return grid.Height * grid.Width * grid.Depth, nil
+	}
+
+	allCells := make(map[Position3D]bool)
+	for _, center := range grid.PositiveCells {
+		minRow := max(0, center.Row-distanceThreshold)
+		maxRow := min(grid.Height-1, center.Row+distanceThreshold)
+		for row := minRow; row <= maxRow; row++ {
+			rowRemaining := distanceThreshold - Abs(row-center.Row)
+			minCol := max(0, center.Column-rowRemaining)
+			maxCol := min(grid.Width-1, center.Column+rowRemaining)
+			for col := minCol; col <= maxCol; col++ {
+				layerRemaining := rowRemaining - Abs(col-center.Column)
+				minLayer := max(0, center.Layer-layerRemaining)
+				maxLayer := min(grid.Depth-1, center.Layer+layerRemaining)
+				for layer := minLayer; layer <= maxLayer; layer++ {
+					allCells[Position3D{Row: row, Column: col, Layer: layer}] = true
+				}
+			}
+		}
+	}
+	return len(allCells), nil
+}
@@ -0,0 +1,98 @@
+package gridneighborhoods_test
+
+import (
+	"math"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestGrid3DValidation(t *testing.T) {
+	if _, err := NewGrid3D(0, 5, 5, nil); err == nil {
+		t.Error("Expected error for zero height")
+	}
+	_, err := NewGrid3D(5, 5, 5, []Position3D{{Row: 0, Column: 0, Layer: 5}})
+	if _, ok := err.(*Position3DOutOfBoundsError); !ok {
+		t.Errorf("Expected Position3DOutOfBoundsError, got %v", err)
+	}
+
+	// Each pair of dimensions fits an int, but the full product does not
+	_, err = NewGrid3D(math.MaxInt/4, 2, 3, nil)
+	if _, ok := err.(*Grid3DTooLargeError); !ok {
+		t.Errorf("Expected Grid3DTooLargeError, got %v", err)
+	}
+
+	// The grid keeps its own copy of the positive cells
+	positives := []Position3D{{Row: 1, Column: 1, Layer: 1}}
+	grid, _ := NewGrid3D(3, 3, 3, positives)
+	positives[0] = Position3D{}
+	if grid.PositiveCells[0] != (Position3D{Row: 1, Column: 1, Layer: 1}) {
+		t.Error("NewGrid3D should not share the caller's slice")
+	}
+}
+
+func TestCount3DNeighborhoodCells(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Full octahedron of radius 2: 1 + 6 + 18 = 25 voxels
+	grid, _ := NewGrid3D(11, 11, 11, []Position3D{{Row: 5, Column: 5, Layer: 5}})
+	if count, err := calculator.Count3DNeighborhoodCells(grid, 2); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+
+	// A depth-1 grid behaves like the 2D case (Scenario 1)
+	flat, _ := NewGrid3D(11, 11, 1, []Position3D{{Row: 5, Column: 5, Layer: 0}})
+	if count, _ := calculator.Count3DNeighborhoodCells(flat, 3); count != 25 {
+		t.Errorf("Expected 25, got %d", count)
+	}
+
+	// Saturating threshold covers every voxel
+	if count, _ := calculator.Count3DNeighborhoodCells(grid, 30); count != 11*11*11 {
+		t.Errorf("Expected %d, got %d", 11*11*11, count)
+	}
+
+	if _, err := calculator.Count3DNeighborhoodCells(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+}
+
+func TestCount3DMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 8).Draw(t, "height")
+		width := rapid.IntRange(1, 8).Draw(t, "width")
+		depth := rapid.IntRange(1, 8).Draw(t, "depth")
+		numPositions := rapid.IntRange(0, 4).Draw(t, "numPositions")
+		positions := make([]Position3D, 0, numPositions)
+		for i := 0; i < numPositions; i++ {
+			positions = append(positions, Position3D{
+				Row:    rapid.IntRange(0, height-1).Draw(t, "pos_row"),
+				Column: rapid.IntRange(0, width-1).Draw(t, "pos_col"),
+				Layer:  rapid.IntRange(0, depth-1).Draw(t, "pos_layer"),
+			})
+		}
+		distanceThreshold := rapid.IntRange(0, 25).Draw(t, "distanceThreshold")
+
+		grid, _ := NewGrid3D(height, width, depth, positions)
+		count, _ := NewNeighborhoodCalculator().Count3DNeighborhoodCells(grid, distanceThreshold)
+
+		expected := 0
+		for row := 0; row < height; row++ {
+			for col := 0; col < width; col++ {
+				for layer := 0; layer < depth; layer++ {
+					cell := Position3D{Row: row, Column: col, Layer: layer}
+					for _, source := range positions {
+						if source.ManhattanDistance(cell) <= distanceThreshold {
+							expected++
+							break
+						}
+					}
+				}
+			}
+		}
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package gridneighborhoods
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadGrid parses a grid from the text format written by SaveGrid.
+//
+// The first non-blank line holds the dimensions as "HxW"; every following
+// non-blank line holds one positive cell as "row col". Lines starting with
+// '#' are comments.
+func LoadGrid(r io.Reader) (*Grid, error) {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	height, width := 0, 0
+	headerRead := false
+	var positiveCells []Position
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !headerRead {
+			h, w, err := parseDimensions(line)
+			if err != nil {
+				return nil, &GridFormatError{Line: lineNumber, Reason: err.Error()}
+			}
+			if h <= 0 || w <= 0 {
+				return nil, &GridFormatError{Line: lineNumber, Err: &InvalidGridDimensionsError{Height: h, Width: w}}
+			}
+			height, width = h, w
+			headerRead = true
+			continue
+		}
+
+		pos, err := parsePositionLine(line)
+		if err != nil {
+			return nil, &GridFormatError{Line: lineNumber, Reason: err.Error()}
+		}
+		if pos.Row < 0 || pos.Row >= height || pos.Column < 0 || pos.Column >= width {
+			return nil, &GridFormatError{Line: lineNumber, Err: &PositionOutOfBoundsError{Position: pos, Height: height, Width: width}}
+		}
+		positiveCells = append(positiveCells, pos)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !headerRead {
+		return nil, &GridFormatError{Line: lineNumber, Reason: "missing \"HxW\" dimensions header"}
+	}
+
+	return NewGrid(height, width, positiveCells)
+}
+
+// SaveGrid writes a grid in the text format read by LoadGrid
+func SaveGrid(w io.Writer, g *Grid) error {
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintf(bw, "%dx%d\n", g.Height, g.Width); err != nil {
+		return err
+	}
+	for _, pos := range g.PositiveCells {
+		if _, err := fmt.Fprintf(bw, "%d %d\n", pos.Row, pos.Column); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// parseDimensions parses a "HxW" header line
+func parseDimensions(line string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(line), "x")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected dimensions as \"HxW\", got %q", line)
+	}
+	height, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height %q", parts[0])
+	}
+	width, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid width %q", parts[1])
+	}
+	return height, width, nil
+}
+
+// parsePositionLine parses a "row col" line
+func parsePositionLine(line string) (Position, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return Position{}, fmt.Errorf("expected \"row col\", got %q", line)
+	}
+	row, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Position{}, fmt.Errorf("invalid row %q", fields[0])
+	}
+	col, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Position{}, fmt.Errorf("invalid column %q", fields[1])
+	}
+	return Position{Row: row, Column: col}, nil
+}
+
+// SaveCoverage writes a set of covered cells as "row col" lines sorted by row, then column
+func SaveCoverage(w io.Writer, cells map[Position]bool) error {
+	bw := bufio.NewWriter(w)
+	for _, pos := range sortedPositions(cells) {
+		if _, err := fmt.Fprintf(bw, "%d %d\n", pos.Row, pos.Column); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadCoverage reads a set of covered cells written by SaveCoverage.
+// Blank lines and lines starting with '#' are ignored.
+func LoadCoverage(r io.Reader) (map[Position]bool, error) {
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	cells := make(map[Position]bool)
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pos, err := parsePositionLine(line)
+		if err != nil {
+			return nil, &GridFormatError{Line: lineNumber, Reason: err.Error()}
+		}
+		cells[pos] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+// WeightedPosition is a source with its own distance threshold
+type WeightedPosition struct {
+	Position Position
+	Radius   int
+}
+
+// weightedGridJSON is the document read by LoadWeightedGridJSON
+type weightedGridJSON struct {
+	Height  int                  `json:"height"`
+	Width   int                  `json:"width"`
+	Sources []weightedSourceJSON `json:"sources"`
+}
+
+// weightedSourceJSON is one source entry of a weightedGridJSON document
+type weightedSourceJSON struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+	Radius int `json:"radius"`
+}
+
+// LoadWeightedGridJSON reads grid dimensions and per-source radii from a JSON document of
+// the form {"height":H,"width":W,"sources":[{"row":r,"column":c,"radius":n},...]}.
+// Unknown fields are rejected so that misspelled keys do not silently default to zero.
+func LoadWeightedGridJSON(r io.Reader) (height, width int, cells []WeightedPosition, err error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var document weightedGridJSON
+	if err := decoder.Decode(&document); err != nil {
+		return 0, 0, nil, err
+	}
+
+	cells = make([]WeightedPosition, len(document.Sources))
+	for i, source := range document.Sources {
+		cells[i] = WeightedPosition{Position: Position{Row: source.Row, Column: source.Column}, Radius: source.Radius}
+	}
+	if err := validateWeightedGrid(document.Height, document.Width, cells); err != nil {
+		return 0, 0, nil, err
+	}
+	return document.Height, document.Width, cells, nil
+}
+
+// SaveWeightedGridJSON writes dimensions and per-source radii in the format read by
+// LoadWeightedGridJSON, after applying the same validation
+func SaveWeightedGridJSON(w io.Writer, height, width int, cells []WeightedPosition) error {
+	if err := validateWeightedGrid(height, width, cells); err != nil {
+		return err
+	}
+	document := weightedGridJSON{Height: height, Width: width, Sources: make([]weightedSourceJSON, len(cells))}
+	for i, cell := range cells {
+		document.Sources[i] = weightedSourceJSON{Row: cell.Position.Row, Column: cell.Position.Column, Radius: cell.Radius}
+	}
+	return json.NewEncoder(w).Encode(document)
+}
+
+// validateWeightedGrid checks the dimensions, that every source lies inside the grid, and
+// that every radius is non-negative
+func validateWeightedGrid(height, width int, cells []WeightedPosition) error {
+	if height <= 0 || width <= 0 {
+		return &InvalidGridDimensionsError{Height: height, Width: width}
+	}
+	for _, cell := range cells {
+		pos := cell.Position
+		if pos.Row < 0 || pos.Row >= height || pos.Column < 0 || pos.Column >= width {
+			return &PositionOutOfBoundsError{Position: pos, Height: height, Width: width}
+		}
+		if cell.Radius < 0 {
+			return &InvalidDistanceThresholdError{Threshold: cell.Radius, Center: &pos}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package gridneighborhoods_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestLoadGridParsesHeaderAndPositions(t *testing.T) {
+	input := "# scenario 4\n11x11\n3 3\n\n4 5\n"
+	grid, err := LoadGrid(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if grid.Height != 11 || grid.Width != 11 {
+		t.Errorf("Expected 11x11, got %dx%d", grid.Height, grid.Width)
+	}
+	if len(grid.PositiveCells) != 2 || grid.PositiveCells[1] != (Position{Row: 4, Column: 5}) {
+		t.Errorf("Unexpected positive cells %v", grid.PositiveCells)
+	}
+}
+
+func TestLoadGridReportsLineNumbers(t *testing.T) {
+	cases := []struct {
+		input string
+		line  int
+	}{
+		{"11by11\n", 1},
+		{"11x11\n3 3\n4\n", 3},
+		{"11x11\n3 three\n", 2},
+		{"11x11\n\n3 11\n", 3},
+		{"0x5\n", 1},
+		{"", 0},
+	}
+
+	for _, tc := range cases {
+		_, err := LoadGrid(strings.NewReader(tc.input))
+		var formatErr *GridFormatError
+		if !errors.As(err, &formatErr) {
+			t.Fatalf("Input %q: expected GridFormatError, got %v", tc.input, err)
+		}
+		if formatErr.Line != tc.line {
+			t.Errorf("Input %q: expected line %d, got %d", tc.input, tc.line, formatErr.Line)
+		}
+	}
+
+	_, err := LoadGrid(strings.NewReader("11x11\n3 11\n"))
+	var boundsErr *PositionOutOfBoundsError
+	if !errors.As(err, &boundsErr) {
+		t.Errorf("Expected wrapped PositionOutOfBoundsError, got %v", err)
+	}
+}
+
+func TestSaveGridRoundTrip(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 10, Column: 9}, {Row: 9, Column: 10}, {Row: 10, Column: 10}})
+
+	var buf bytes.Buffer
+	if err := SaveGrid(&buf, grid); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	loaded, err := LoadGrid(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.Height != grid.Height || loaded.Width != grid.Width || len(loaded.PositiveCells) != len(grid.PositiveCells) {
+		t.Fatalf("Round trip mismatch: got %dx%d with %v", loaded.Height, loaded.Width, loaded.PositiveCells)
+	}
+	for i, pos := range grid.PositiveCells {
+		if loaded.PositiveCells[i] != pos {
+			t.Errorf("Position %d: expected %v, got %v", i, pos, loaded.PositiveCells[i])
+		}
+	}
+}
+
+func TestSaveCoverageRoundTrip(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	cells := NewNeighborhoodCalculator().GetNeighborhoodCells(grid, 2)
+
+	var buf bytes.Buffer
+	if err := SaveCoverage(&buf, cells); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "1 3\n2 2\n") {
+		t.Errorf("Expected sorted output, got %q", buf.String())
+	}
+
+	loaded, err := LoadCoverage(&buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded) != len(cells) {
+		t.Fatalf("Expected %d cells, got %d", len(cells), len(loaded))
+	}
+	for pos := range cells {
+		if !loaded[pos] {
+			t.Errorf("Missing cell %v after round trip", pos)
+		}
+	}
+}
+
+func TestLoadCoverageReportsLineNumbers(t *testing.T) {
+	_, err := LoadCoverage(strings.NewReader("1 3\n2 x\n"))
+	var formatErr *GridFormatError
+	if !errors.As(err, &formatErr) || formatErr.Line != 2 {
+		t.Errorf("Expected GridFormatError on line 2, got %v", err)
+	}
+}
+
+func TestWeightedGridJSONRoundTrip(t *testing.T) {
+	input := `{"height": 11, "width": 11, "sources": [{"row": 3, "column": 3, "radius": 2}, {"row": 4, "column": 5, "radius": 0}]}`
+	height, width, cells, err := LoadWeightedGridJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []WeightedPosition{
+		{Position: Position{Row: 3, Column: 3}, Radius: 2},
+		{Position: Position{Row: 4, Column: 5}, Radius: 0},
+	}
+	if height != 11 || width != 11 || len(cells) != 2 || cells[0] != expected[0] || cells[1] != expected[1] {
+		t.Fatalf("Unexpected result %dx%d %v", height, width, cells)
+	}
+
+	var buf bytes.Buffer
+	if err := SaveWeightedGridJSON(&buf, height, width, cells); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	height, width, loaded, err := LoadWeightedGridJSON(&buf)
+	if err != nil || height != 11 || width != 11 || len(loaded) != 2 || loaded[0] != expected[0] || loaded[1] != expected[1] {
+		t.Errorf("Round trip mismatch: %dx%d %v (err=%v)", height, width, loaded, err)
+	}
+}
+
+func TestWeightedGridJSONValidation(t *testing.T) {
+	var thresholdErr *InvalidDistanceThresholdError
+	_, _, _, err := LoadWeightedGridJSON(strings.NewReader(`{"height": 5, "width": 5, "sources": [{"row": 1, "column": 2, "radius": -1}]}`))
+	if !errors.As(err, &thresholdErr) || thresholdErr.Center == nil || *thresholdErr.Center != (Position{Row: 1, Column: 2}) {
+		t.Errorf("Expected InvalidDistanceThresholdError for (1,2), got %v", err)
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	if _, _, _, err := LoadWeightedGridJSON(strings.NewReader(`{"height": 5, "width": 5, "sources": [{"row": 5, "column": 0}]}`)); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, _, _, err := LoadWeightedGridJSON(strings.NewReader(`{"width": 5}`)); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError, got %v", err)
+	}
+
+	// A misspelled key is an error rather than a silent zero radius
+	if _, _, _, err := LoadWeightedGridJSON(strings.NewReader(`{"height": 5, "width": 5, "sources": [{"row": 1, "column": 1, "raduis": 3}]}`)); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+
+	if err := SaveWeightedGridJSON(&bytes.Buffer{}, 5, 5, []WeightedPosition{{Radius: -2}}); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError from save, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package gridneighborhoods
+
+// GridLike is the read-only view of a grid needed by the core counting methods, letting
+// grids with custom backing storage be counted directly. *Grid implements it.
+// IsValidPosition must agree with Dimensions. The source accessor is called Positives
+// because Grid already exports a PositiveCells field.
+type GridLike interface {
+	Dimensions() (height, width int)
+	IsValidPosition(pos Position) bool
+	Positives() []Position
+}
+
+// Dimensions returns the grid's height and width
+func (g *Grid) Dimensions() (height, width int) {
+	return g.Height, g.Width
+}
+
+// Positives returns the grid's positive cells
+func (g *Grid) Positives() []Position {
+	return g.PositiveCells
+}
+
+// resolveGrid returns the *Grid behind a GridLike. Other implementations are wrapped in an
+// unmasked Grid with their dimensions and positive cells, which are validated first.
+// A nil GridLike or nil *Grid yields ErrNilGrid.
+func resolveGrid(gl GridLike) (*Grid, error) {
+	switch grid := gl.(type) {
+	case nil:
+		return nil, ErrNilGrid
+	case *Grid:
+		if grid == nil {
+			return nil, ErrNilGrid
+		}
+		return grid, nil
+	}
+
+	height, width := gl.Dimensions()
+	if height <= 0 || width <= 0 {
+		return nil, &InvalidGridDimensionsError{Height: height, Width: width}
+	}
+	positives := gl.Positives()
+	for _, pos := range positives {
+		if !gl.IsValidPosition(pos) {
+			return nil, &PositionOutOfBoundsError{Position: pos, Height: height, Width: width}
+		}
+	}
+	return &Grid{Height: height, Width: width, PositiveCells: positives, positive: positiveSet(positives)}, nil
+}
@@ -0,0 +1,379 @@
+package gridneighborhoods_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestNewGridSorted(t *testing.T) {
+	input := []Position{{Row: 10, Column: 10}, {Row: 9, Column: 10}, {Row: 10, Column: 9}, {Row: 0, Column: 5}}
+	grid, err := NewGridSorted(11, 11, input)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []Position{{Row: 0, Column: 5}, {Row: 9, Column: 10}, {Row: 10, Column: 9}, {Row: 10, Column: 10}}
+	for i, pos := range expected {
+		if grid.PositiveCells[i] != pos {
+			t.Fatalf("Index %d: expected %v, got %v", i, pos, grid.PositiveCells[i])
+		}
+	}
+	if input[0] != (Position{Row: 10, Column: 10}) {
+		t.Error("NewGridSorted should not reorder the caller's slice")
+	}
+
+	if _, err := NewGridSorted(11, 11, []Position{{Row: 11, Column: 0}}); err == nil {
+		t.Error("Expected validation error for out-of-bounds position")
+	}
+}
+
+func TestNewGridRejectsTooLargeGrids(t *testing.T) {
+	// Overflowing products are always rejected
+	_, err := NewGrid(math.MaxInt/2, 3, nil)
+	var tooLarge *GridTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Errorf("Expected GridTooLargeError for overflowing dimensions, got %v", err)
+	}
+	if _, err := NewGrid(math.MaxInt/3, 3, nil); err != nil {
+		t.Errorf("Expected no limit beyond overflow, got %v", err)
+	}
+}
+
+func TestWithMaxGridCellsLimitsDenseOutputs(t *testing.T) {
+	calculator := NewNeighborhoodCalculator(WithMaxGridCells(100))
+	fits, _ := NewGrid(10, 10, []Position{{Row: 5, Column: 5}})
+	if matrix, err := calculator.CoverageMatrix(fits, 2); err != nil || len(matrix) != 10 {
+		t.Errorf("Expected a 10x10 matrix within a 100-cell limit, got %d rows (err=%v)", len(matrix), err)
+	}
+
+	tooBig, _ := NewGrid(10, 11, []Position{{Row: 5, Column: 5}})
+	var tooLarge *GridTooLargeError
+	if matrix, err := calculator.CoverageMatrix(tooBig, 2); !errors.As(err, &tooLarge) || tooLarge.Limit != 100 || matrix != nil {
+		t.Errorf("Expected GridTooLargeError from CoverageMatrix, got %d rows (err=%v)", len(matrix), err)
+	}
+	if err := calculator.RenderASCII(&bytes.Buffer{}, tooBig, 2); !errors.As(err, &tooLarge) || tooLarge.Limit != 100 {
+		t.Errorf("Expected GridTooLargeError with limit 100, got %v", err)
+	}
+	var buf bytes.Buffer
+	if err := calculator.RenderGnuplot(&buf, tooBig, 2); !errors.As(err, &tooLarge) || buf.Len() != 0 {
+		t.Errorf("Expected GridTooLargeError from RenderGnuplot with no output, got %v", err)
+	}
+	// Counting is not a dense output and ignores the limit
+	if count, err := calculator.CountNeighborhoodCells(tooBig, 2); err != nil || count != 13 {
+		t.Errorf("Expected count 13, got %d (err=%v)", count, err)
+	}
+
+	// A non-positive limit removes it
+	if matrix, err := NewNeighborhoodCalculator(WithMaxGridCells(0)).CoverageMatrix(tooBig, 2); err != nil || len(matrix) != 10 {
+		t.Errorf("Expected an unlimited matrix, got %d rows (err=%v)", len(matrix), err)
+	}
+}
+
+func TestNewGridFromMatrix(t *testing.T) {
+	grid, err := NewGridFromMatrix([][]byte{
+		[]byte("..#"),
+		[]byte("#.."),
+	}, '#')
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if grid.Height != 2 || grid.Width != 3 {
+		t.Errorf("Expected 2x3, got %dx%d", grid.Height, grid.Width)
+	}
+	expected := []Position{{Row: 0, Column: 2}, {Row: 1, Column: 0}}
+	if len(grid.PositiveCells) != 2 || grid.PositiveCells[0] != expected[0] || grid.PositiveCells[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, grid.PositiveCells)
+	}
+
+	_, err = NewGridFromMatrix([][]byte{[]byte("..."), []byte("..")}, '#')
+	var ragged *RaggedMatrixError
+	if !errors.As(err, &ragged) || ragged.Row != 1 {
+		t.Errorf("Expected RaggedMatrixError on row 1, got %v", err)
+	}
+
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, err := NewGridFromMatrix(nil, '#'); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError for empty matrix, got %v", err)
+	}
+	if _, err := NewGridFromMatrix([][]byte{{}}, '#'); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError for zero-width matrix, got %v", err)
+	}
+}
+
+// bitmapGrid is a GridLike backed by a packed bit set instead of a position slice
+type bitmapGrid struct {
+	height, width int
+	bits          []uint64
+}
+
+func (b *bitmapGrid) Dimensions() (int, int) { return b.height, b.width }
+
+func (b *bitmapGrid) IsValidPosition(pos Position) bool {
+	return pos.Row >= 0 && pos.Row < b.height && pos.Column >= 0 && pos.Column < b.width
+}
+
+func (b *bitmapGrid) Positives() []Position {
+	var positives []Position
+	for index := 0; index < b.height*b.width; index++ {
+		if b.bits[index/64]&(1<<(index%64)) != 0 {
+			positives = append(positives, Position{Row: index / b.width, Column: index % b.width})
+		}
+	}
+	return positives
+}
+
+func (b *bitmapGrid) set(pos Position) {
+	index := pos.Row*b.width + pos.Column
+	b.bits[index/64] |= 1 << (index % 64)
+}
+
+func TestGridLikeCustomStorage(t *testing.T) {
+	// Scenario 4 sources stored in a bitmap
+	custom := &bitmapGrid{height: 11, width: 11, bits: make([]uint64, 2)}
+	custom.set(Position{Row: 3, Column: 3})
+	custom.set(Position{Row: 4, Column: 5})
+
+	calculator := NewNeighborhoodCalculator()
+	if count, err := calculator.CountNeighborhoodCells(custom, 2); err != nil || count != 22 {
+		t.Errorf("Expected 22, got %d (err=%v)", count, err)
+	}
+	if cells := calculator.GetNeighborhoodCells(custom, 2); len(cells) != 22 {
+		t.Errorf("Expected 22 cells, got %d", len(cells))
+	}
+	if cells, err := calculator.CollectNeighborhoodCells(custom, 2); err != nil || len(cells) != 22 {
+		t.Errorf("Expected 22 cells, got %d (err=%v)", len(cells), err)
+	}
+
+	var concrete GridLike = &Grid{Height: 11, Width: 11, PositiveCells: custom.Positives()}
+	if count, _ := calculator.CountNeighborhoodCells(concrete, 2); count != 22 {
+		t.Errorf("Expected *Grid to satisfy GridLike with 22, got %d", count)
+	}
+
+	var nilGrid *Grid
+	if _, err := calculator.CountNeighborhoodCells(nilGrid, 2); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid for typed nil, got %v", err)
+	}
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, err := calculator.CountNeighborhoodCells(&bitmapGrid{}, 2); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError, got %v", err)
+	}
+}
+
+func TestNewGridStrictRejectsDuplicates(t *testing.T) {
+	positions := []Position{{Row: 1, Column: 1}, {Row: 2, Column: 2}, {Row: 1, Column: 1}, {Row: 2, Column: 2}, {Row: 2, Column: 2}}
+	_, err := NewGridStrict(5, 5, positions)
+	var duplicate *DuplicatePositionError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("Expected DuplicatePositionError, got %v", err)
+	}
+	if duplicate.Position != (Position{Row: 1, Column: 1}) || duplicate.Count != 2 {
+		t.Errorf("Expected first duplicate (1,1) x2, got %v x%d", duplicate.Position, duplicate.Count)
+	}
+
+	grid, err := NewGridStrict(5, 5, positions[:2])
+	if err != nil || len(grid.PositiveCells) != 2 {
+		t.Errorf("Expected distinct cells to be accepted, got %v (err=%v)", grid, err)
+	}
+
+	// Bounds are still checked first
+	var boundsErr *PositionOutOfBoundsError
+	if _, err := NewGridStrict(5, 5, []Position{{Row: 9, Column: 0}, {Row: 9, Column: 0}}); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+}
+
+func TestCellCount(t *testing.T) {
+	grid, _ := NewGrid(11, 7, nil)
+	if grid.CellCount() != 77 {
+		t.Errorf("Expected 77, got %d", grid.CellCount())
+	}
+	// Literal grids bypass NewGrid's limits, so the product must not overflow
+	huge := &Grid{Height: math.MaxInt / 2, Width: 3}
+	if huge.CellCount() != math.MaxInt {
+		t.Errorf("Expected saturation at math.MaxInt, got %d", huge.CellCount())
+	}
+	if (&Grid{Height: -1, Width: 5}).CellCount() != 0 {
+		t.Error("Expected 0 cells for an invalid grid")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	grid, _ := NewGrid(11, 10, []Position{{Row: 0, Column: 0}, {Row: 1, Column: 1}, {Row: 5, Column: 9}, {Row: 10, Column: 4}})
+	coarse, err := Downsample(grid, 3, 4)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// 11 rows in blocks of 3 round up to 4; 10 columns in blocks of 4 round up to 3
+	if coarse.Height != 4 || coarse.Width != 3 {
+		t.Errorf("Expected 4x3, got %dx%d", coarse.Height, coarse.Width)
+	}
+	expected := []Position{{Row: 0, Column: 0}, {Row: 1, Column: 2}, {Row: 3, Column: 1}}
+	if len(coarse.PositiveCells) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, coarse.PositiveCells)
+	}
+	for i, pos := range expected {
+		if coarse.PositiveCells[i] != pos {
+			t.Errorf("Expected %v, got %v", expected, coarse.PositiveCells)
+			break
+		}
+	}
+
+	if same, _ := Downsample(grid, 1, 1); same.Height != 11 || same.Width != 10 || len(same.PositiveCells) != 4 {
+		t.Errorf("Expected 1x1 blocks to keep the grid, got %dx%d with %v", same.Height, same.Width, same.PositiveCells)
+	}
+	var blockErr *InvalidBlockSizeError
+	if _, err := Downsample(grid, 0, 2); !errors.As(err, &blockErr) {
+		t.Errorf("Expected InvalidBlockSizeError, got %v", err)
+	}
+	if _, err := Downsample(nil, 2, 2); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestNewGridCopiesPositiveCells(t *testing.T) {
+	positions := []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}}
+	grid, _ := NewGrid(11, 11, positions)
+
+	// Mutating the caller's slice, even out of bounds, must not reach the grid
+	positions[0] = Position{Row: 100, Column: 100}
+	positions = append(positions[:1], Position{Row: 0, Column: 0})
+	if grid.PositiveCells[0] != (Position{Row: 3, Column: 3}) || grid.PositiveCells[1] != (Position{Row: 4, Column: 5}) {
+		t.Fatalf("Expected grid cells unchanged, got %v", grid.PositiveCells)
+	}
+	if count, err := NewNeighborhoodCalculator().CountNeighborhoodCells(grid, 2); err != nil || count != 22 {
+		t.Errorf("Expected 22, got %d (err=%v)", count, err)
+	}
+}
+
+func TestIsPositive(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}, {Row: 3, Column: 3}})
+	for _, tt := range []struct {
+		pos      Position
+		expected bool
+	}{
+		{Position{Row: 3, Column: 3}, true},
+		{Position{Row: 4, Column: 5}, true},
+		{Position{Row: 5, Column: 4}, false},
+		{Position{Row: -1, Column: 3}, false},
+	} {
+		if got := grid.IsPositive(tt.pos); got != tt.expected {
+			t.Errorf("IsPositive(%v): expected %v, got %v", tt.pos, tt.expected, got)
+		}
+	}
+
+	// A grid assembled without NewGrid still answers correctly
+	literal := &Grid{Height: 5, Width: 5, PositiveCells: []Position{{Row: 1, Column: 2}}}
+	if !literal.IsPositive(Position{Row: 1, Column: 2}) || literal.IsPositive(Position{Row: 2, Column: 1}) {
+		t.Error("Expected literal grid lookups to scan PositiveCells")
+	}
+
+	dilated := NewNeighborhoodCalculator().Dilate(grid, 1)
+	if !dilated.IsPositive(Position{Row: 2, Column: 3}) || dilated.IsPositive(Position{Row: 0, Column: 0}) {
+		t.Error("Expected dilated grid to report its covered cells as positive")
+	}
+}
+
+func TestSetPositiveCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+	cells := []Position{{Row: 4, Column: 5}, {Row: 3, Column: 3}, {Row: 4, Column: 5}}
+	if err := grid.SetPositiveCells(cells); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Position{{Row: 4, Column: 5}, {Row: 3, Column: 3}}
+	if len(grid.PositiveCells) != 2 || grid.PositiveCells[0] != expected[0] || grid.PositiveCells[1] != expected[1] {
+		t.Errorf("Expected deduplicated %v, got %v", expected, grid.PositiveCells)
+	}
+	if !grid.IsPositive(Position{Row: 3, Column: 3}) || grid.IsPositive(Position{Row: 0, Column: 0}) {
+		t.Error("Expected IsPositive to reflect the new cells")
+	}
+	if count, _ := NewNeighborhoodCalculator().CountNeighborhoodCells(grid, 2); count != 22 {
+		t.Errorf("Expected Scenario 4 count 22, got %d", count)
+	}
+
+	// The grid keeps its own copy
+	cells[1] = Position{Row: 9, Column: 9}
+	if grid.PositiveCells[1] != expected[1] {
+		t.Error("Expected SetPositiveCells to copy its input")
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	if err := grid.SetPositiveCells([]Position{{Row: 1, Column: 1}, {Row: 11, Column: 0}}); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	grid.AddBlockedRect(8, 8, 9, 9)
+	var blockedErr *BlockedPositiveCellError
+	if err := grid.SetPositiveCells([]Position{{Row: 8, Column: 9}}); !errors.As(err, &blockedErr) {
+		t.Errorf("Expected BlockedPositiveCellError, got %v", err)
+	}
+	if len(grid.PositiveCells) != 2 || !grid.IsPositive(Position{Row: 4, Column: 5}) || grid.IsPositive(Position{Row: 1, Column: 1}) {
+		t.Error("Expected rejected calls to leave the grid unchanged")
+	}
+}
+
+func TestAppendRowAndColumn(t *testing.T) {
+	grid, _ := NewGrid(3, 4, []Position{{Row: 2, Column: 3}})
+	grid.AddBlockedRect(0, 0, 0, 0)
+	calculator := NewNeighborhoodCalculator()
+
+	taller := grid.AppendRow()
+	if taller.Height != 4 || taller.Width != 4 || grid.Height != 3 {
+		t.Fatalf("Expected a 4x4 copy of a 3x4 grid, got %dx%d from %dx%d", taller.Height, taller.Width, grid.Height, grid.Width)
+	}
+	if !taller.IsPositive(Position{Row: 2, Column: 3}) || !taller.IsBlocked(Position{Row: 0, Column: 0}) {
+		t.Error("Expected positive and blocked cells to carry over")
+	}
+	// The corner source at N=1 gains the cell above it in the new row
+	before, _ := calculator.CountNeighborhoodCells(grid, 1)
+	after, _ := calculator.CountNeighborhoodCells(taller, 1)
+	if before != 3 || after != 4 {
+		t.Errorf("Expected counts 3 then 4, got %d then %d", before, after)
+	}
+
+	wider := taller.AppendColumn()
+	if wider.Height != 4 || wider.Width != 5 || taller.Width != 4 {
+		t.Errorf("Expected a 4x5 copy, got %dx%d", wider.Height, wider.Width)
+	}
+	if count, _ := calculator.CountNeighborhoodCells(wider, 1); count != 5 {
+		t.Errorf("Expected the full 5-cell diamond, got %d", count)
+	}
+
+	// The copies do not share mutable state with the original
+	wider.AddBlockedRect(1, 1, 1, 1)
+	if grid.IsBlocked(Position{Row: 1, Column: 1}) || taller.IsBlocked(Position{Row: 1, Column: 1}) {
+		t.Error("Expected blocking a copy to leave the original unchanged")
+	}
+}
+
+func TestNewGridBetween(t *testing.T) {
+	// Corners in either order give the same 4x3 region starting at (10,20)
+	grid, err := NewGridBetween(Position{Row: 13, Column: 20}, Position{Row: 10, Column: 22}, []Position{{Row: 10, Column: 20}, {Row: 12, Column: 21}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if grid.Height != 4 || grid.Width != 3 {
+		t.Errorf("Expected 4x3, got %dx%d", grid.Height, grid.Width)
+	}
+	if len(grid.PositiveCells) != 2 || grid.PositiveCells[0] != (Position{}) || grid.PositiveCells[1] != (Position{Row: 2, Column: 1}) {
+		t.Errorf("Expected shifted cells [(0,0) (2,1)], got %v", grid.PositiveCells)
+	}
+
+	// Negative corners work too; equal corners give a single cell
+	grid, _ = NewGridBetween(Position{Row: -3, Column: -3}, Position{Row: -3, Column: -3}, []Position{{Row: -3, Column: -3}})
+	if grid.Height != 1 || grid.Width != 1 || !grid.IsPositive(Position{}) {
+		t.Errorf("Expected a 1x1 grid with its only cell positive, got %dx%d %v", grid.Height, grid.Width, grid.PositiveCells)
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	_, err = NewGridBetween(Position{Row: 0, Column: 0}, Position{Row: 2, Column: 2}, []Position{{Row: 3, Column: 0}})
+	if !errors.As(err, &boundsErr) || boundsErr.Position != (Position{Row: 3, Column: 0}) {
+		t.Errorf("Expected PositionOutOfBoundsError for (3,0), got %v", err)
+	}
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, err := NewGridBetween(Position{Row: math.MinInt, Column: 0}, Position{Row: math.MaxInt, Column: 0}, nil); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError for overflowing corners, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package gridneighborhoods
+
+import "slices"
+
+// IncrementalNeighborhood tracks a neighborhood union while sources are added, removed,
+// and moved. It records how many sources cover each cell, so every update only touches
+// the neighborhoods of the sources involved. The grid supplies dimensions and the blocked
+// mask; its PositiveCells seed the sources and are not modified afterwards. Cells listed in
+// its Excluded field at creation are never counted as covered.
+type IncrementalNeighborhood struct {
+	grid              *Grid
+	distanceThreshold int
+
+	// sources counts each source position, allowing duplicates
+	sources map[Position]int
+
+	// coverCount holds, for every covered cell, the number of sources covering it
+	coverCount map[Position]int
+
+	// excluded is the grid's excluded set when the state was created
+	excluded map[Position]bool
+}
+
+// NewIncrementalNeighborhood creates incremental state seeded with the grid's positive cells
+func (nc *NeighborhoodCalculator) NewIncrementalNeighborhood(grid *Grid, distanceThreshold int) (*IncrementalNeighborhood, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	state := &IncrementalNeighborhood{
+		grid:              grid,
+		distanceThreshold: distanceThreshold,
+		sources:           make(map[Position]int),
+		coverCount:        make(map[Position]int),
+		excluded:          grid.excludedCells(),
+	}
+	for _, pos := range grid.PositiveCells {
+		nc.applySource(state, pos, 1)
+	}
+	return state, nil
+}
+
+// Count returns the number of unique cells covered by the current sources
+func (s *IncrementalNeighborhood) Count() int {
+	return len(s.coverCount)
+}
+
+// Sources returns the current sources sorted by row, then column, repeating duplicates
+func (s *IncrementalNeighborhood) Sources() []Position {
+	var sources []Position
+	for pos, n := range s.sources {
+		for range n {
+			sources = append(sources, pos)
+		}
+	}
+	slices.SortFunc(sources, comparePositions)
+	return sources
+}
+
+// AddSource adds a source at pos, which must be an unblocked in-bounds cell
+func (nc *NeighborhoodCalculator) AddSource(state *IncrementalNeighborhood, pos Position) error {
+	if err := nc.validateSource(state, pos); err != nil {
+		return err
+	}
+	nc.applySource(state, pos, 1)
+	return nil
+}
+
+// RemoveSource removes one source at pos, returning a SourceNotFoundError if there is none
+func (nc *NeighborhoodCalculator) RemoveSource(state *IncrementalNeighborhood, pos Position) error {
+	if state.sources[pos] == 0 {
+		return &SourceNotFoundError{Position: pos}
+	}
+	nc.applySource(state, pos, -1)
+	return nil
+}
+
+// MoveSource moves one source from from to to, updating only the two neighborhoods involved.
+// The state is unchanged if either position is rejected.
+func (nc *NeighborhoodCalculator) MoveSource(state *IncrementalNeighborhood, from, to Position) error {
+	if state.sources[from] == 0 {
+		return &SourceNotFoundError{Position: from}
+	}
+	if err := nc.validateSource(state, to); err != nil {
+		return err
+	}
+	if from == to {
+		return nil
+	}
+	nc.applySource(state, to, 1)
+	nc.applySource(state, from, -1)
+	return nil
+}
+
+// validateSource checks that pos can hold a source
+func (nc *NeighborhoodCalculator) validateSource(state *IncrementalNeighborhood, pos Position) error {
+	if !nc.boundaryHandler.IsWithinBounds(pos, state.grid) {
+		return &PositionOutOfBoundsError{Position: pos, Height: state.grid.Height, Width: state.grid.Width}
+	}
+	if state.grid.IsBlocked(pos) {
+		return &BlockedPositiveCellError{Position: pos}
+	}
+	return nil
+}
+
+// applySource adds (delta 1) or removes (delta -1) one source's contribution to the cover counts
+func (nc *NeighborhoodCalculator) applySource(state *IncrementalNeighborhood, pos Position, delta int) {
+	state.sources[pos] += delta
+	if state.sources[pos] == 0 {
+		delete(state.sources, pos)
+	}
+	nc.scanNeighborhood(state.grid, pos, state.distanceThreshold, func(cell Position) bool {
+		if state.excluded[cell] {
+			return true
+		}
+		state.coverCount[cell] += delta
+		if state.coverCount[cell] == 0 {
+			delete(state.coverCount, cell)
+		}
+		return true
+	})
+}
@@ -0,0 +1,88 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestIncrementalNeighborhoodMoveSource(t *testing.T) {
+	// Scenario 4: (3,3) and (4,5) with N=2 cover 22 cells
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	state, err := calculator.NewIncrementalNeighborhood(grid, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state.Count() != 22 {
+		t.Fatalf("Expected 22, got %d", state.Count())
+	}
+
+	// Moving the second source on top of the first leaves a single diamond
+	if err := calculator.MoveSource(state, Position{Row: 4, Column: 5}, Position{Row: 3, Column: 3}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if state.Count() != 13 {
+		t.Errorf("Expected 13 after merging sources, got %d", state.Count())
+	}
+	if err := calculator.RemoveSource(state, Position{Row: 3, Column: 3}); err != nil || state.Count() != 13 {
+		t.Errorf("Expected the duplicate source to keep 13, got %d (err=%v)", state.Count(), err)
+	}
+	calculator.RemoveSource(state, Position{Row: 3, Column: 3})
+	if state.Count() != 0 || len(state.Sources()) != 0 {
+		t.Errorf("Expected empty state, got %d cells and %v", state.Count(), state.Sources())
+	}
+
+	var notFound *SourceNotFoundError
+	if err := calculator.MoveSource(state, Position{Row: 3, Column: 3}, Position{}); !errors.As(err, &notFound) {
+		t.Errorf("Expected SourceNotFoundError, got %v", err)
+	}
+	var boundsErr *PositionOutOfBoundsError
+	if err := calculator.AddSource(state, Position{Row: 11, Column: 0}); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	grid.AddBlockedRect(0, 0, 0, 0)
+	var blockedErr *BlockedPositiveCellError
+	if err := calculator.AddSource(state, Position{Row: 0, Column: 0}); !errors.As(err, &blockedErr) {
+		t.Errorf("Expected BlockedPositiveCellError, got %v", err)
+	}
+}
+
+func TestIncrementalNeighborhoodMatchesFreshCount(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 12, 4)
+		threshold := rapid.IntRange(0, 5).Draw(t, "threshold")
+		calculator := NewNeighborhoodCalculator()
+		state, _ := calculator.NewIncrementalNeighborhood(grid, threshold)
+
+		drawPos := func(label string) Position {
+			return Position{
+				Row:    rapid.IntRange(0, grid.Height-1).Draw(t, label+"_row"),
+				Column: rapid.IntRange(0, grid.Width-1).Draw(t, label+"_col"),
+			}
+		}
+		steps := rapid.IntRange(0, 10).Draw(t, "steps")
+		for range steps {
+			sources := state.Sources()
+			switch op := rapid.IntRange(0, 2).Draw(t, "op"); {
+			case op == 0 || len(sources) == 0:
+				calculator.AddSource(state, drawPos("add"))
+			case op == 1:
+				from := sources[rapid.IntRange(0, len(sources)-1).Draw(t, "remove")]
+				calculator.RemoveSource(state, from)
+			default:
+				from := sources[rapid.IntRange(0, len(sources)-1).Draw(t, "from")]
+				calculator.MoveSource(state, from, drawPos("to"))
+			}
+
+			fresh, _ := NewGrid(grid.Height, grid.Width, state.Sources())
+			expected, _ := calculator.CountNeighborhoodCells(fresh, threshold)
+			if state.Count() != expected {
+				t.Fatalf("Expected %d, got %d for sources %v", expected, state.Count(), state.Sources())
+			}
+		}
+	})
+}
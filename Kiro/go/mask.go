@@ -0,0 +1,124 @@
+package gridneighborhoods
+
+// AddBlockedRect masks the inclusive rectangle [minRow,maxRow] x [minCol,maxCol] so its
+// cells are never counted as covered. The rectangle is clipped to the grid boundaries.
+// A rectangle containing a positive cell is rejected and leaves the mask unchanged.
+func (g *Grid) AddBlockedRect(minRow, minCol, maxRow, maxCol int) error {
+	if minRow > maxRow || minCol > maxCol {
+		return &InvalidRectangleError{MinRow: minRow, MinCol: minCol, MaxRow: maxRow, MaxCol: maxCol}
+	}
+
+	minRow, maxRow = max(0, minRow), min(g.Height-1, maxRow)
+	minCol, maxCol = max(0, minCol), min(g.Width-1, maxCol)
+
+	// Validate positive cells before mutating the mask
+	for _, pos := range g.PositiveCells {
+		if pos.Row >= minRow && pos.Row <= maxRow && pos.Column >= minCol && pos.Column <= maxCol {
+			return &BlockedPositiveCellError{Position: pos}
+		}
+	}
+
+	if g.blocked == nil {
+		g.blocked = make(map[Position]bool)
+	}
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			g.blocked[Position{Row: row, Column: col}] = true
+		}
+	}
+	return nil
+}
+
+// IsBlocked checks if a position is masked by a blocked rectangle
+func (g *Grid) IsBlocked(pos Position) bool {
+	return g.blocked[pos]
+}
+
+// excludedCells returns the distinct in-bounds, unblocked cells listed in Excluded, or nil
+// when there are none
+func (g *Grid) excludedCells() map[Position]bool {
+	var excluded map[Position]bool
+	for _, pos := range g.Excluded {
+		if !g.IsValidPosition(pos) || g.IsBlocked(pos) {
+			continue
+		}
+		if excluded == nil {
+			excluded = make(map[Position]bool)
+		}
+		excluded[pos] = true
+	}
+	return excluded
+}
+
+// BlockedCellCount returns the number of masked cells in the grid
+func (g *Grid) BlockedCellCount() int {
+	return len(g.blocked)
+}
+
+// CountVisibleNeighborhoodCells counts the cells within the threshold of some positive
+// cell that also have a clear line of sight to it: the Bresenham line from the source to
+// the cell must not pass through a blocked cell. Blocked cells thus cast shadows instead
+// of only being left out. Lines are traced within the grid, ignoring any wrapped topology.
+// Excluded cells are left out of the count as in CountNeighborhoodCells.
+func (nc *NeighborhoodCalculator) CountVisibleNeighborhoodCells(grid *Grid, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	excluded := grid.excludedCells()
+	visible := make(map[Position]bool)
+	reach := min(distanceThreshold, grid.MaxManhattanDistance())
+	for _, center := range grid.PositiveCells {
+		minRow := max(0, center.Row-reach)
+		maxRow := min(grid.Height-1, center.Row+reach)
+		for row := minRow; row <= maxRow; row++ {
+			remaining := reach - Abs(row-center.Row)
+			minCol := max(0, center.Column-remaining)
+			maxCol := min(grid.Width-1, center.Column+remaining)
+			for col := minCol; col <= maxCol; col++ {
+				pos := Position{Row: row, Column: col}
+				if visible[pos] || excluded[pos] || grid.IsBlocked(pos) {
+					continue
+				}
+				if grid.hasLineOfSight(center, pos) {
+					visible[pos] = true
+				}
+			}
+		}
+	}
+	return len(visible), nil
+}
+
+// hasLineOfSight reports whether no cell strictly between from and to on their Bresenham
+// line is blocked
+func (g *Grid) hasLineOfSight(from, to Position) bool {
+	rowDelta, colDelta := -Abs(to.Row-from.Row), Abs(to.Column-from.Column)
+	rowStep, colStep := 1, 1
+	if to.Row < from.Row {
+		rowStep = -1
+	}
+	if to.Column < from.Column {
+		colStep = -1
+	}
+
+	pos := from
+	err := colDelta + rowDelta
+	for pos != to {
+		if pos != from && g.IsBlocked(pos) {
+			return false
+		}
+		doubled := 2 * err
+		if doubled >= rowDelta {
+			err += rowDelta
+			pos.Column += colStep
+		}
+		if doubled <= colDelta {
+			err += colDelta
+			pos.Row += rowStep
+		}
+	}
+	return true
+}
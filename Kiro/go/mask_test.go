@@ -0,0 +1,278 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestBlockedRectExcludedFromNeighborhood(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	// Block the whole row directly above the source
+	if err := grid.AddBlockedRect(6, 0, 6, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	calculator := NewNeighborhoodCalculator()
+	cells := calculator.GetNeighborhoodCells(grid, 3)
+	for pos := range cells {
+		if grid.IsBlocked(pos) {
+			t.Fatalf("Blocked cell %v should not be covered", pos)
+		}
+	}
+
+	// Row 6 contributes 5 cells to the 25-cell diamond
+	count, _ := calculator.CountNeighborhoodCells(grid, 3)
+	if count != 20 {
+		t.Errorf("Expected 20, got %d", count)
+	}
+}
+
+func TestBlockedRectWithSaturatingThreshold(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+	if err := grid.AddBlockedRect(8, 8, 20, 20); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if grid.BlockedCellCount() != 9 {
+		t.Errorf("Expected rectangle clipped to 9 cells, got %d", grid.BlockedCellCount())
+	}
+
+	calculator := NewNeighborhoodCalculator()
+	count, _ := calculator.CountNeighborhoodCells(grid, 100)
+	if count != 121-9 {
+		t.Errorf("Expected %d, got %d", 121-9, count)
+	}
+	if len(calculator.GetNeighborhoodCells(grid, 100)) != count {
+		t.Error("GetNeighborhoodCells should agree with CountNeighborhoodCells")
+	}
+}
+
+func TestBlockedRectRejectsPositiveCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+
+	err := grid.AddBlockedRect(4, 4, 6, 6)
+	var blockedErr *BlockedPositiveCellError
+	if !errors.As(err, &blockedErr) {
+		t.Fatalf("Expected BlockedPositiveCellError, got %v", err)
+	}
+	if grid.BlockedCellCount() != 0 {
+		t.Error("Rejected rectangle should leave the mask unchanged")
+	}
+
+	var rectErr *InvalidRectangleError
+	if !errors.As(grid.AddBlockedRect(3, 3, 2, 2), &rectErr) {
+		t.Error("Expected InvalidRectangleError for inverted rectangle")
+	}
+}
+
+func TestExcludedCellsDroppedFromUnion(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	// The source itself, a duplicate, and an out-of-bounds position are all tolerated
+	grid.Excluded = []Position{{Row: 5, Column: 5}, {Row: 5, Column: 6}, {Row: 5, Column: 6}, {Row: 20, Column: 20}}
+	calculator := NewNeighborhoodCalculator()
+
+	cells := calculator.GetNeighborhoodCells(grid, 2)
+	if len(cells) != 11 {
+		t.Errorf("Expected 11 cells, got %d", len(cells))
+	}
+	if cells[Position{Row: 5, Column: 6}] || cells[Position{Row: 5, Column: 5}] {
+		t.Error("Expected excluded cells to be dropped")
+	}
+	// Excluded cells do not stop the spread past them
+	if !cells[Position{Row: 5, Column: 7}] {
+		t.Error("Expected (5,7) to be covered through the excluded (5,6)")
+	}
+	if count, _ := calculator.CountNeighborhoodCells(grid, 2); count != 11 {
+		t.Errorf("Expected count 11, got %d", count)
+	}
+	// Per-source enumeration is unaffected
+	if size, _ := calculator.CountSingleNeighborhood(grid, Position{Row: 5, Column: 5}, 2); size != 13 {
+		t.Errorf("Expected single neighborhood 13, got %d", size)
+	}
+	// The saturating shortcut also honors exclusions
+	if count, _ := calculator.CountNeighborhoodCells(grid, 100); count != 119 {
+		t.Errorf("Expected saturated count 119, got %d", count)
+	}
+}
+
+func TestExcludedCellsHonoredByUnionQueries(t *testing.T) {
+	// A 1x5 strip with a source at (0,0) and (0,1) excluded covers {(0,0), (0,2)} at N=2
+	grid, _ := NewGrid(1, 5, []Position{{Row: 0, Column: 0}})
+	grid.Excluded = []Position{{Row: 0, Column: 1}}
+	calculator := NewNeighborhoodCalculator()
+
+	if !calculator.CoverageAtLeast(grid, 2, 2) || calculator.CoverageAtLeast(grid, 2, 3) {
+		t.Error("Expected CoverageAtLeast to reach 2 but not 3 cells")
+	}
+	// The saturating shortcut covers 4 of the 5 cells
+	if !calculator.CoverageAtLeast(grid, 10, 4) || calculator.CoverageAtLeast(grid, 10, 5) {
+		t.Error("Expected saturated CoverageAtLeast to reach 4 but not 5 cells")
+	}
+	if count, err := calculator.CountWithExternalSources(grid, []Position{{Row: 0, Column: 3}}, 2); err != nil || count != 4 {
+		t.Errorf("Expected 4 cells with an external source, got %d (err=%v)", count, err)
+	}
+	if gain, err := calculator.MarginalGain(grid, Position{Row: 0, Column: 2}, 2); err != nil || gain != 2 {
+		t.Errorf("Expected gain 2, got %d (err=%v)", gain, err)
+	}
+	if chosen, coverage, err := calculator.GreedyPlacement(grid, 1, 2); err != nil || len(chosen) != 1 || chosen[0] != (Position{Row: 0, Column: 2}) || coverage != 4 {
+		t.Errorf("Expected (0,2) covering 4, got %v with %d (err=%v)", chosen, coverage, err)
+	}
+	if report, err := calculator.Analyze(grid, 2); err != nil || report.Count != 2 || report.ComponentCount != 2 || report.Bounds.Max != (Position{Row: 0, Column: 2}) {
+		t.Errorf("Expected a 2-cell report in two components, got %+v (err=%v)", report, err)
+	}
+	coverage, err := calculator.ComputeCoverage(grid, 2)
+	if err != nil || coverage.Count != 2 || coverage.Cells[Position{Row: 0, Column: 1}] {
+		t.Errorf("Expected 2 covered cells without (0,1), got %+v (err=%v)", coverage, err)
+	}
+	// Per-source sizes still include the excluded cell
+	if coverage != nil && (len(coverage.SourceSizes) != 1 || coverage.SourceSizes[0] != 3) {
+		t.Errorf("Expected source sizes [3], got %v", coverage.SourceSizes)
+	}
+
+	state, _ := calculator.NewIncrementalNeighborhood(grid, 2)
+	if state.Count() != 2 {
+		t.Errorf("Expected incremental count 2, got %d", state.Count())
+	}
+	calculator.AddSource(state, Position{Row: 0, Column: 4})
+	if state.Count() != 4 {
+		t.Errorf("Expected incremental count 4 after adding (0,4), got %d", state.Count())
+	}
+}
+
+func TestExcludedCellsHonoredByCounters(t *testing.T) {
+	// Same strip as above: every counter must agree with CountNeighborhoodCells' 2
+	grid, _ := NewGrid(1, 5, []Position{{Row: 0, Column: 0}})
+	grid.Excluded = []Position{{Row: 0, Column: 1}}
+	calculator := NewNeighborhoodCalculator()
+
+	tests := []struct {
+		name  string
+		count func() (int, error)
+	}{
+		{"Dynamic", func() (int, error) {
+			return calculator.CountNeighborhoodCellsDynamic(grid, func(Position) int { return 2 })
+		}},
+		{"TwoClass", func() (int, error) {
+			return calculator.CountNeighborhoodCellsTwoClass(grid, grid.PositiveCells, 2, nil, 0)
+		}},
+		{"Common", func() (int, error) {
+			return calculator.CountCommonNeighborhoodCells(grid, 2)
+		}},
+		{"FromShapes", func() (int, error) {
+			return calculator.CountNeighborhoodCellsFromShapes(grid, []Shape{CellShape{Position: Position{Row: 0, Column: 0}}}, 2)
+		}},
+		{"Chamfer", func() (int, error) {
+			return calculator.CountChamferNeighborhoodCells(grid, 1, 1, 2)
+		}},
+		{"WeightedManhattan", func() (int, error) {
+			return calculator.CountWeightedManhattanCells(grid, 1, 1, 2)
+		}},
+	}
+	for _, tt := range tests {
+		if count, err := tt.count(); err != nil || count != 2 {
+			t.Errorf("%s: expected 2, got %d (err=%v)", tt.name, count, err)
+		}
+	}
+}
+
+func TestExcludedCellsDroppedFromCoverageMaps(t *testing.T) {
+	grid, _ := NewGrid(1, 5, []Position{{Row: 0, Column: 0}})
+	grid.Excluded = []Position{{Row: 0, Column: 1}}
+	calculator := NewNeighborhoodCalculator()
+	excluded := Position{Row: 0, Column: 1}
+
+	if owners := calculator.FirstCoverer(grid, 2); len(owners) != 2 {
+		t.Errorf("Expected 2 owned cells, got %v", owners)
+	}
+	if labels := calculator.LabelCoverage(grid, []LabeledPosition{{Position: Position{Row: 0, Column: 0}, Label: "a"}}, 2); len(labels) != 2 || labels[excluded] != "" {
+		t.Errorf("Expected 2 labeled cells without (0,1), got %v", labels)
+	}
+	if field := calculator.InfluenceField(grid, 2); len(field) != 2 {
+		t.Errorf("Expected 2 influenced cells, got %v", field)
+	}
+	if multiplicity := calculator.CoverageMultiplicity(grid, 2); len(multiplicity) != 2 {
+		t.Errorf("Expected 2 cells in the multiplicity map, got %v", multiplicity)
+	}
+	if score, err := calculator.CoverageScore(grid, 2, 1); err != nil || score != 2 {
+		t.Errorf("Expected score 2, got %v (err=%v)", score, err)
+	}
+}
+
+func TestExcludedCellsMatchBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 12, 4)
+		numExcluded := rapid.IntRange(0, 6).Draw(t, "numExcluded")
+		excluded := make(map[Position]bool)
+		for i := 0; i < numExcluded; i++ {
+			pos := Position{
+				Row:    rapid.IntRange(0, grid.Height-1).Draw(t, "excluded_row"),
+				Column: rapid.IntRange(0, grid.Width-1).Draw(t, "excluded_col"),
+			}
+			grid.Excluded = append(grid.Excluded, pos)
+			excluded[pos] = true
+		}
+		distanceThreshold := rapid.IntRange(0, 25).Draw(t, "distanceThreshold")
+
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			return !excluded[cell] && source.ManhattanDistance(cell) <= distanceThreshold
+		})
+		calculator := NewNeighborhoodCalculator()
+		count, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+		if cells := calculator.GetNeighborhoodCells(grid, distanceThreshold); len(cells) != expected {
+			t.Fatalf("Expected %d cells, got %d", expected, len(cells))
+		}
+	})
+}
+
+func TestCountVisibleNeighborhoodCellsShadow(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	if count, err := calculator.CountVisibleNeighborhoodCells(grid, 3); err != nil || count != 25 {
+		t.Errorf("Expected the full 25-cell diamond, got %d (err=%v)", count, err)
+	}
+
+	// An obstacle right of the source hides itself and the two cells behind it on the row
+	grid.AddBlockedRect(5, 6, 5, 6)
+	if count, _ := calculator.CountVisibleNeighborhoodCells(grid, 3); count != 22 {
+		t.Errorf("Expected 22 visible cells, got %d", count)
+	}
+	// Plain masking only drops the obstacle itself
+	if count, _ := calculator.CountNeighborhoodCells(grid, 3); count != 24 {
+		t.Errorf("Expected 24 masked cells, got %d", count)
+	}
+
+	// A second source on the far side sees the shadowed cells
+	grid, _ = NewGrid(11, 11, []Position{{Row: 5, Column: 5}, {Row: 5, Column: 9}})
+	grid.AddBlockedRect(5, 6, 5, 6)
+	visible, _ := calculator.CountVisibleNeighborhoodCells(grid, 3)
+	masked, _ := calculator.CountNeighborhoodCells(grid, 3)
+	if visible != masked {
+		t.Errorf("Expected every masked-covered cell to be visible from some source, got %d vs %d", visible, masked)
+	}
+
+	if _, err := calculator.CountVisibleNeighborhoodCells(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, err := calculator.CountVisibleNeighborhoodCells(nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestCountVisibleWithoutObstaclesMatchesCount(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		distanceThreshold := rapid.IntRange(0, 20).Draw(t, "distanceThreshold")
+		calculator := NewNeighborhoodCalculator()
+		visible, _ := calculator.CountVisibleNeighborhoodCells(grid, distanceThreshold)
+		expected, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if visible != expected {
+			t.Fatalf("Expected %d, got %d", expected, visible)
+		}
+	})
+}
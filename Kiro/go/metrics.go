@@ -0,0 +1,198 @@
+package gridneighborhoods
+
+// CountChamferNeighborhoodCells counts the unique cells whose chamfer distance to some
+// positive cell is <= distanceThreshold. Orthogonal steps cost a and diagonal steps cost b.
+// With a=1, b=1 the neighborhood is the Chebyshev square; with b >= 2*a diagonal moves are
+// never used and the neighborhood is the Manhattan diamond of radius distanceThreshold/a.
+// Excluded cells are left out as in CountNeighborhoodCells.
+func (nc *NeighborhoodCalculator) CountChamferNeighborhoodCells(grid *Grid, a, b, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if a <= 0 {
+		return 0, &InvalidMoveCostError{Name: "orthogonal", Cost: a}
+	}
+	if b <= 0 {
+		return 0, &InvalidMoveCostError{Name: "diagonal", Cost: b}
+	}
+
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		neighborhood := nc.chamferNeighborhood(grid, center, a, b, distanceThreshold)
+		for cell := range neighborhood {
+			if !excluded[cell] {
+				allCells[cell] = true
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// chamferNeighborhood enumerates the cells within chamfer distance N of center
+func (nc *NeighborhoodCalculator) chamferNeighborhood(grid *Grid, center Position, a, b, distanceThreshold int) map[Position]bool {
+	neighborhood := make(map[Position]bool)
+
+	// No cell farther than N/min(a,b) steps along either axis can be reached, and clamping
+	// to the grid's extent keeps the bounds from overflowing
+	reach := min(distanceThreshold/min(a, b), max(grid.Height, grid.Width))
+	minRow := max(0, center.Row-reach)
+	maxRow := min(grid.Height-1, center.Row+reach)
+	minCol := max(0, center.Column-reach)
+	maxCol := min(grid.Width-1, center.Column+reach)
+
+	for row := minRow; row <= maxRow; row++ {
+		for col := minCol; col <= maxCol; col++ {
+			pos := Position{Row: row, Column: col}
+			if grid.IsBlocked(pos) {
+				continue
+			}
+			if nc.distanceCalculator.CalculateChamferDistance(center, pos, a, b) <= distanceThreshold {
+				neighborhood[pos] = true
+			}
+		}
+	}
+	return neighborhood
+}
+
+// CountWeightedManhattanCells counts the unique cells satisfying
+// vCost*|rowDiff| + hCost*|colDiff| <= distanceThreshold for some positive cell.
+// With vCost == hCost == 1 this equals CountNeighborhoodCells, which also leaves out
+// excluded cells.
+func (nc *NeighborhoodCalculator) CountWeightedManhattanCells(grid *Grid, vCost, hCost, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if vCost <= 0 {
+		return 0, &InvalidMoveCostError{Name: "vertical", Cost: vCost}
+	}
+	if hCost <= 0 {
+		return 0, &InvalidMoveCostError{Name: "horizontal", Cost: hCost}
+	}
+
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		// Clamp the row range, then each row's column range, to the grid. The reaches are
+		// clamped first so huge thresholds cannot overflow the bounds.
+		rowReach := min(distanceThreshold/vCost, grid.Height-1)
+		minRow := max(0, center.Row-rowReach)
+		maxRow := min(grid.Height-1, center.Row+rowReach)
+		for row := minRow; row <= maxRow; row++ {
+			colReach := min((distanceThreshold-vCost*Abs(row-center.Row))/hCost, grid.Width-1)
+			minCol := max(0, center.Column-colReach)
+			maxCol := min(grid.Width-1, center.Column+colReach)
+			for col := minCol; col <= maxCol; col++ {
+				pos := Position{Row: row, Column: col}
+				if !grid.IsBlocked(pos) && !excluded[pos] {
+					allCells[pos] = true
+				}
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// CountCombinedMetricCells counts the unique cells that lie within Manhattan distance
+// manhattanN of some positive cell or within Chebyshev distance chebyshevN of it, i.e. the
+// union of a diamond and a square around every source. Excluded cells are left out as in
+// CountNeighborhoodCells.
+func (nc *NeighborhoodCalculator) CountCombinedMetricCells(grid *Grid, manhattanN, chebyshevN int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	for _, threshold := range []int{manhattanN, chebyshevN} {
+		if threshold < 0 {
+			return 0, &InvalidDistanceThresholdError{Threshold: threshold}
+		}
+	}
+
+	// Neither shape reaches past the grid's extent, so clamping keeps the loops finite
+	reach := min(max(manhattanN, chebyshevN), grid.MaxManhattanDistance())
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		minRow := max(0, center.Row-reach)
+		maxRow := min(grid.Height-1, center.Row+reach)
+		minCol := max(0, center.Column-reach)
+		maxCol := min(grid.Width-1, center.Column+reach)
+		for row := minRow; row <= maxRow; row++ {
+			for col := minCol; col <= maxCol; col++ {
+				pos := Position{Row: row, Column: col}
+				rowDiff, colDiff := Abs(row-center.Row), Abs(col-center.Column)
+				if grid.IsBlocked(pos) || excluded[pos] || (rowDiff+colDiff > manhattanN && max(rowDiff, colDiff) > chebyshevN) {
+					continue
+				}
+				allCells[pos] = true
+			}
+		}
+	}
+	return len(allCells), nil
+}
+
+// CountCrossNeighborhoodCells counts the cells in the thick cross of some positive cell:
+// every cell whose row is within rowThreshold of the source's row, spanning the full grid
+// width, or whose column is within colThreshold of the source's column, spanning the full
+// height. Unions of such corridors are counted from the covered rows and columns alone,
+// without enumerating cells.
+func (nc *NeighborhoodCalculator) CountCrossNeighborhoodCells(grid *Grid, rowThreshold, colThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	for _, threshold := range []int{rowThreshold, colThreshold} {
+		if threshold < 0 {
+			return 0, &InvalidDistanceThresholdError{Threshold: threshold}
+		}
+	}
+	if !grid.HasPositiveCells() {
+		return 0, nil
+	}
+
+	coveredRows := coveredBands(grid.PositiveCells, grid.Height, rowThreshold, func(pos Position) int { return pos.Row })
+	coveredCols := coveredBands(grid.PositiveCells, grid.Width, colThreshold, func(pos Position) int { return pos.Column })
+	inCross := func(pos Position) bool { return coveredRows[pos.Row] || coveredCols[pos.Column] }
+
+	rowCount, colCount := 0, 0
+	for _, covered := range coveredRows {
+		if covered {
+			rowCount++
+		}
+	}
+	for _, covered := range coveredCols {
+		if covered {
+			colCount++
+		}
+	}
+	count := rowCount*grid.Width + colCount*grid.Height - rowCount*colCount
+	for pos := range grid.blocked {
+		if inCross(pos) {
+			count--
+		}
+	}
+	for pos := range grid.excludedCells() {
+		if inCross(pos) {
+			count--
+		}
+	}
+	return count, nil
+}
+
+// coveredBands marks the coordinates 0..extent-1 lying within threshold of the coordinate
+// coord selects from some source
+func coveredBands(sources []Position, extent, threshold int, coord func(Position) int) []bool {
+	covered := make([]bool, extent)
+	// A band wider than the axis covers all of it, and clamping keeps the bounds in range
+	threshold = min(threshold, extent)
+	for _, pos := range sources {
+		for i := max(0, coord(pos)-threshold); i <= min(extent-1, coord(pos)+threshold); i++ {
+			covered[i] = true
+		}
+	}
+	return covered
+}
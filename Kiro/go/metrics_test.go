@@ -0,0 +1,254 @@
+package gridneighborhoods_test
+
+import (
+	"math"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+// drawGrid generates a grid with up to maxPositions (possibly repeated) positive cells
+func drawGrid(t *rapid.T, maxDim, maxPositions int) *Grid {
+	height := rapid.IntRange(1, maxDim).Draw(t, "height")
+	width := rapid.IntRange(1, maxDim).Draw(t, "width")
+	numPositions := rapid.IntRange(0, maxPositions).Draw(t, "numPositions")
+	positions := make([]Position, 0, numPositions)
+	for i := 0; i < numPositions; i++ {
+		row := rapid.IntRange(0, height-1).Draw(t, "pos_row")
+		col := rapid.IntRange(0, width-1).Draw(t, "pos_col")
+		positions = append(positions, Position{Row: row, Column: col})
+	}
+	grid, _ := NewGrid(height, width, positions)
+	return grid
+}
+
+// bruteForceCount counts cells for which within reports true against some positive cell
+func bruteForceCount(grid *Grid, within func(source, cell Position) bool) int {
+	count := 0
+	for row := 0; row < grid.Height; row++ {
+		for col := 0; col < grid.Width; col++ {
+			cell := Position{Row: row, Column: col}
+			for _, source := range grid.PositiveCells {
+				if within(source, cell) {
+					count++
+					break
+				}
+			}
+		}
+	}
+	return count
+}
+
+func TestChamferUnitCostsEqualsChebyshev(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		distanceThreshold := rapid.IntRange(0, 25).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, err := calculator.CountChamferNeighborhoodCells(grid, 1, 1, distanceThreshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			return max(Abs(source.Row-cell.Row), Abs(source.Column-cell.Column)) <= distanceThreshold
+		})
+		if count != expected {
+			t.Fatalf("Expected Chebyshev count %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestChamferWithoutDiagonalsEqualsManhattan(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		distanceThreshold := rapid.IntRange(0, 25).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, _ := calculator.CountChamferNeighborhoodCells(grid, 1, 2, distanceThreshold)
+		expected, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if count != expected {
+			t.Fatalf("Expected Manhattan count %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestChamfer3_4Scenario1(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// Classic 3-4 chamfer with radius 6: the center, one or two orthogonal steps in each
+	// direction, and the four diagonal neighbors
+	count, _ := calculator.CountChamferNeighborhoodCells(grid, 3, 4, 6)
+	if count != 13 {
+		t.Errorf("Expected 13, got %d", count)
+	}
+
+	if _, err := calculator.CountChamferNeighborhoodCells(grid, 0, 4, 6); err == nil {
+		t.Error("Expected error for zero orthogonal cost")
+	}
+}
+
+func TestChamferHugeThresholdCoversGrid(t *testing.T) {
+	grid, _ := NewGrid(5, 5, []Position{{Row: 2, Column: 2}})
+	calculator := NewNeighborhoodCalculator()
+	for _, costs := range [][2]int{{1, 1}, {3, 4}} {
+		if count, err := calculator.CountChamferNeighborhoodCells(grid, costs[0], costs[1], math.MaxInt); err != nil || count != 25 {
+			t.Errorf("Costs %v: expected 25, got %d (err=%v)", costs, count, err)
+		}
+	}
+}
+
+func TestWeightedManhattanEqualCostsMatchesManhattan(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		distanceThreshold := rapid.IntRange(0, 40).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, err := calculator.CountWeightedManhattanCells(grid, 1, 1, distanceThreshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestWeightedManhattanMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		vCost := rapid.IntRange(1, 5).Draw(t, "vCost")
+		hCost := rapid.IntRange(1, 5).Draw(t, "hCost")
+		distanceThreshold := rapid.IntRange(0, 40).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, _ := calculator.CountWeightedManhattanCells(grid, vCost, hCost, distanceThreshold)
+		distanceCalculator := NewDistanceCalculator()
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			return distanceCalculator.CalculateWeightedManhattanDistance(source, cell, vCost, hCost) <= distanceThreshold
+		})
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestWeightedManhattanCheaperVertical(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// Vertical cost 1, horizontal cost 2, budget 2: the center column spans rows 3..7,
+	// and only the source row can afford one step to either side
+	count, _ := calculator.CountWeightedManhattanCells(grid, 1, 2, 2)
+	if count != 7 {
+		t.Errorf("Expected 7, got %d", count)
+	}
+
+	if _, err := calculator.CountWeightedManhattanCells(grid, 1, 0, 2); err == nil {
+		t.Error("Expected error for zero horizontal cost")
+	}
+}
+
+func TestWeightedManhattanHugeThresholdCoversGrid(t *testing.T) {
+	grid, _ := NewGrid(5, 5, []Position{{Row: 2, Column: 2}})
+	calculator := NewNeighborhoodCalculator()
+	if count, err := calculator.CountWeightedManhattanCells(grid, 1, 1, math.MaxInt); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+	if count, err := calculator.CountWeightedManhattanCells(grid, 1, 2, math.MaxInt-1); err != nil || count != 25 {
+		t.Errorf("Expected 25 with unequal costs, got %d (err=%v)", count, err)
+	}
+}
+
+func TestCountCombinedMetricCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// A radius-3 diamond (25 cells) plus the 5x5 square of Chebyshev radius 2 adds the
+	// four square corners the diamond misses
+	if count, err := calculator.CountCombinedMetricCells(grid, 3, 2); err != nil || count != 29 {
+		t.Errorf("Expected 29, got %d (err=%v)", count, err)
+	}
+	// Each shape alone when the other is contained in it
+	if count, _ := calculator.CountCombinedMetricCells(grid, 2, 0); count != 13 {
+		t.Errorf("Expected the 13-cell diamond, got %d", count)
+	}
+	if count, _ := calculator.CountCombinedMetricCells(grid, 1, 3); count != 49 {
+		t.Errorf("Expected the 49-cell square, got %d", count)
+	}
+
+	if _, err := calculator.CountCombinedMetricCells(grid, -1, 2); err == nil {
+		t.Error("Expected error for negative Manhattan threshold")
+	}
+	if _, err := calculator.CountCombinedMetricCells(grid, 2, -1); err == nil {
+		t.Error("Expected error for negative Chebyshev threshold")
+	}
+
+	// Excluded cells are dropped as in CountNeighborhoodCells and CountCrossNeighborhoodCells
+	strip, _ := NewGrid(1, 5, []Position{{Row: 0, Column: 0}})
+	strip.Excluded = []Position{{Row: 0, Column: 1}}
+	expected, _ := calculator.CountNeighborhoodCells(strip, 2)
+	if count, err := calculator.CountCombinedMetricCells(strip, 2, 1); err != nil || count != expected || count != 2 {
+		t.Errorf("Expected %d with an excluded cell, got %d (err=%v)", expected, count, err)
+	}
+}
+
+func TestCountCombinedMetricMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 5)
+		manhattanN := rapid.IntRange(0, 25).Draw(t, "manhattanN")
+		chebyshevN := rapid.IntRange(0, 25).Draw(t, "chebyshevN")
+
+		count, _ := NewNeighborhoodCalculator().CountCombinedMetricCells(grid, manhattanN, chebyshevN)
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			rowDiff, colDiff := Abs(source.Row-cell.Row), Abs(source.Column-cell.Column)
+			return rowDiff+colDiff <= manhattanN || max(rowDiff, colDiff) <= chebyshevN
+		})
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestCountCrossNeighborhoodCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	// A 3-row band and a 1-column band across an 11x11 grid: 33 + 11 - 3 shared cells
+	if count, err := calculator.CountCrossNeighborhoodCells(grid, 1, 0); err != nil || count != 41 {
+		t.Errorf("Expected 41, got %d (err=%v)", count, err)
+	}
+
+	// Blocked cells inside the cross are left out
+	grid.AddBlockedRect(0, 0, 0, 10)
+	if count, _ := calculator.CountCrossNeighborhoodCells(grid, 1, 0); count != 40 {
+		t.Errorf("Expected 40 with the bottom row blocked, got %d", count)
+	}
+
+	if _, err := calculator.CountCrossNeighborhoodCells(grid, -1, 0); err == nil {
+		t.Error("Expected error for negative row threshold")
+	}
+	if _, err := calculator.CountCrossNeighborhoodCells(nil, 1, 1); err == nil {
+		t.Error("Expected error for nil grid")
+	}
+}
+
+func TestCountCrossMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 4)
+		rowThreshold := rapid.IntRange(0, 25).Draw(t, "rowThreshold")
+		colThreshold := rapid.IntRange(0, 25).Draw(t, "colThreshold")
+
+		count, _ := NewNeighborhoodCalculator().CountCrossNeighborhoodCells(grid, rowThreshold, colThreshold)
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			return Abs(source.Row-cell.Row) <= rowThreshold || Abs(source.Column-cell.Column) <= colThreshold
+		})
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package gridneighborhoods
+
+import (
+	"maps"
+	"slices"
+)
+
+// Dilate performs a morphological dilation of the positive cells by a Manhattan (diamond)
+// structuring element of the given radius. It returns a new grid of the same size, and
+// with the same blocked cells, excluded cells, and origin, whose positive cells are every
+// covered cell sorted by row, then column. It returns nil for a nil grid or a negative radius.
+func (nc *NeighborhoodCalculator) Dilate(grid *Grid, radius int) *Grid {
+	if grid == nil || radius < 0 {
+		return nil
+	}
+	covered := nc.GetNeighborhoodCells(grid, radius)
+	return &Grid{
+		Height:        grid.Height,
+		Width:         grid.Width,
+		PositiveCells: sortedPositions(covered),
+		Origin:        grid.Origin,
+		Excluded:      slices.Clone(grid.Excluded),
+		blocked:       maps.Clone(grid.blocked),
+		positive:      covered,
+	}
+}
@@ -0,0 +1,41 @@
+package gridneighborhoods_test
+
+import (
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestDilate(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	dilated := calculator.Dilate(grid, 3)
+	if dilated.Height != 11 || dilated.Width != 11 {
+		t.Fatalf("Expected 11x11, got %dx%d", dilated.Height, dilated.Width)
+	}
+	if len(dilated.PositiveCells) != 25 {
+		t.Errorf("Expected 25 positive cells, got %d", len(dilated.PositiveCells))
+	}
+	if len(grid.PositiveCells) != 1 {
+		t.Error("Dilate should not modify the input grid")
+	}
+
+	// Dilation composes: radius 1 then radius 2 equals radius 3
+	twice := calculator.Dilate(calculator.Dilate(grid, 1), 2)
+	if len(twice.PositiveCells) != 25 {
+		t.Errorf("Expected 25 cells after composed dilation, got %d", len(twice.PositiveCells))
+	}
+
+	// Origin and exclusions carry over, so excluded cells never become sources
+	grid.Origin = BottomLeft
+	grid.Excluded = []Position{{Row: 5, Column: 6}}
+	dilated = calculator.Dilate(grid, 1)
+	if dilated.Origin != BottomLeft || len(dilated.Excluded) != 1 || dilated.IsPositive(Position{Row: 5, Column: 6}) {
+		t.Errorf("Expected origin and exclusions kept, got origin %v, excluded %v", dilated.Origin, dilated.Excluded)
+	}
+
+	if calculator.Dilate(grid, -1) != nil || calculator.Dilate(nil, 1) != nil {
+		t.Error("Expected nil for invalid input")
+	}
+}
@@ -1,99 +1,535 @@
 package gridneighborhoods
 
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
 // NeighborhoodCalculator calculates neighborhood cells for positive cells in a grid
 type NeighborhoodCalculator struct {
 	distanceCalculator *DistanceCalculator
 	boundaryHandler    *BoundaryHandler
+
+	// maxCells caps the size of a neighborhood union; 0 means unlimited
+	maxCells int
+
+	// maxGridCells caps height*width for dense outputs; 0 means unlimited
+	maxGridCells int64
+
+	// requirePositiveCells makes counting a grid without sources an error
+	requirePositiveCells bool
+
+	// topology selects the axes that wrap around
+	topology Topology
+
+	// boundaryMode records whether edges clip, reflect, or wrap
+	boundaryMode BoundaryMode
+
+	// tieBreakSeed, when set, randomizes GreedyPlacement tie-breaking reproducibly
+	tieBreakSeed *uint64
+
+	// sampleSeed seeds the cell sampling of EstimateCoverage
+	sampleSeed uint64
+}
+
+// CalculatorOption configures a NeighborhoodCalculator
+type CalculatorOption func(*NeighborhoodCalculator)
+
+// WithMaxCells limits neighborhood unions to at most n cells. Exceeding the limit makes
+// CountNeighborhoodCells and CollectNeighborhoodCells return a ResultTooLargeError.
+// A value of n <= 0 removes the limit.
+func WithMaxCells(n int) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.maxCells = max(0, n)
+	}
+}
+
+// DefaultMaxGridCells is the default WithMaxGridCells limit. It is an int64 because it
+// exceeds the int range on 32-bit platforms, where every grid fits within it.
+const DefaultMaxGridCells int64 = 1 << 32
+
+// WithMaxGridCells limits the grids accepted by dense outputs, which allocate one entry per
+// cell, to at most n cells. CoverageMatrix, RenderASCII, RenderPNG, and RenderGnuplot
+// return a GridTooLargeError for larger grids. The default is DefaultMaxGridCells; a value
+// of n <= 0 removes the limit.
+func WithMaxGridCells(n int64) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		if n < 0 {
+			n = 0
+		}
+		nc.maxGridCells = n
+	}
+}
+
+// checkGridCells returns a GridTooLargeError if grid exceeds the WithMaxGridCells limit
+func (nc *NeighborhoodCalculator) checkGridCells(grid *Grid) error {
+	if nc.maxGridCells > 0 && int64(grid.CellCount()) > nc.maxGridCells {
+		// A limit below the cell count always fits in an int
+		return &GridTooLargeError{Height: grid.Height, Width: grid.Width, Limit: int(nc.maxGridCells)}
+	}
+	return nil
+}
+
+// WithRequirePositiveCells makes CountNeighborhoodCells return a NoPositiveCellsError for
+// grids without positive cells instead of a count of 0
+func WithRequirePositiveCells() CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.requirePositiveCells = true
+	}
+}
+
+// WithTieBreakSeed makes GreedyPlacement break ties between equal-gain candidates with a
+// random source seeded by seed. Runs with the same seed choose the same positions.
+func WithTieBreakSeed(seed uint64) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.tieBreakSeed = &seed
+	}
+}
+
+// WithSampleSeed sets the seed EstimateCoverage samples cells with; the default is 0.
+// Estimates with the same seed, grid, threshold, and fraction are identical.
+func WithSampleSeed(seed uint64) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.sampleSeed = seed
+	}
 }
 
 // NewNeighborhoodCalculator creates a new neighborhood calculator
-func NewNeighborhoodCalculator() *NeighborhoodCalculator {
-	return &NeighborhoodCalculator{
+func NewNeighborhoodCalculator(opts ...CalculatorOption) *NeighborhoodCalculator {
+	nc := &NeighborhoodCalculator{
 		distanceCalculator: NewDistanceCalculator(),
 		boundaryHandler:    NewBoundaryHandler(),
+		maxGridCells:       DefaultMaxGridCells,
+	}
+	for _, opt := range opts {
+		opt(nc)
 	}
+	return nc
 }
 
 // CountNeighborhoodCells counts the total unique cells in all neighborhoods
-func (nc *NeighborhoodCalculator) CountNeighborhoodCells(grid *Grid, distanceThreshold int) (int, error) {
+func (nc *NeighborhoodCalculator) CountNeighborhoodCells(gl GridLike, distanceThreshold int) (int, error) {
+	grid, err := resolveGrid(gl)
+	if err != nil {
+		return 0, err
+	}
 	// Validate distance threshold
 	if distanceThreshold < 0 {
 		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
 	}
 
 	// Handle empty positive cells case
-	if len(grid.PositiveCells) == 0 {
+	if !grid.HasPositiveCells() {
+		if nc.requirePositiveCells {
+			return 0, &NoPositiveCellsError{Height: grid.Height, Width: grid.Width}
+		}
 		return 0, nil
 	}
 
 	// Optimization 1: Early termination - if distance threshold exceeds grid dimensions,
 	// all grid cells will be included (when at least one positive cell exists)
-	maxPossibleDistance := (grid.Height - 1) + (grid.Width - 1)
-	if distanceThreshold >= maxPossibleDistance {
-		return grid.Height * grid.Width, nil
+	if distanceThreshold >= grid.MaxManhattanDistance() {
+		count := grid.CellCount() - grid.BlockedCellCount() - len(grid.excludedCells())
+		if nc.maxCells > 0 && count > nc.maxCells {
+			return 0, &ResultTooLargeError{Limit: nc.maxCells}
+		}
+		return count, nil
+	}
+
+	// Optimization 3: a single source has a closed-form clipped diamond size
+	if len(grid.PositiveCells) == 1 && len(grid.Excluded) == 0 && nc.closedFormApplies(grid, distanceThreshold) {
+		count := singleNeighborhoodSize(grid, grid.PositiveCells[0], distanceThreshold)
+		if nc.maxCells > 0 && count > nc.maxCells {
+			return 0, &ResultTooLargeError{Limit: nc.maxCells}
+		}
+		return count, nil
 	}
 
 	// Get all neighborhood cells
-	cells := nc.GetNeighborhoodCells(grid, distanceThreshold)
+	cells, err := nc.collectNeighborhoodCells(grid, distanceThreshold, nc.maxCells)
+	if err != nil {
+		return 0, err
+	}
 	return len(cells), nil
 }
 
-// GetNeighborhoodCells returns the set of all unique cells in neighborhoods
-func (nc *NeighborhoodCalculator) GetNeighborhoodCells(grid *Grid, distanceThreshold int) map[Position]bool {
+// CountNeighborhoodCellsDetailed is like CountNeighborhoodCells but also returns the
+// effective threshold, min(distanceThreshold, grid.MaxManhattanDistance()). Any larger
+// threshold covers no more cells, so an effective threshold below the requested one flags
+// a wastefully large request.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsDetailed(gl GridLike, distanceThreshold int) (count, effectiveThreshold int, err error) {
+	grid, err := resolveGrid(gl)
+	if err != nil {
+		return 0, 0, err
+	}
+	count, err = nc.CountNeighborhoodCells(grid, distanceThreshold)
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, min(distanceThreshold, grid.MaxManhattanDistance()), nil
+}
+
+// MustCountNeighborhoodCells is like CountNeighborhoodCells but panics with the error
+// instead of returning it. It is intended for hot loops over inputs that have already
+// been validated; CountNeighborhoodCells remains the primary API.
+func (nc *NeighborhoodCalculator) MustCountNeighborhoodCells(grid *Grid, distanceThreshold int) int {
+	count, err := nc.CountNeighborhoodCells(grid, distanceThreshold)
+	if err != nil {
+		panic(err)
+	}
+	return count
+}
+
+// maxClosedFormThreshold keeps the squared terms of singleNeighborhoodSize from overflowing
+const maxClosedFormThreshold = 1 << 30
+
+// CountSingleNeighborhood returns the size of center's clipped neighborhood in O(1) using
+// edge-clipping arithmetic. A threshold reaching the farthest cell from center yields the
+// whole unblocked grid directly. Otherwise masked grids, wrapped topologies, and thresholds
+// too large for the arithmetic fall back to enumeration, so the result always matches
+// EnumerateNeighborhood.
+func (nc *NeighborhoodCalculator) CountSingleNeighborhood(grid *Grid, center Position, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if !nc.boundaryHandler.IsWithinBounds(center, grid) {
+		return 0, &PositionOutOfBoundsError{Position: center, Height: grid.Height, Width: grid.Width}
+	}
+	if distanceThreshold >= nc.farthestDistance(grid, center) {
+		return grid.CellCount() - grid.BlockedCellCount(), nil
+	}
+	if !nc.closedFormApplies(grid, distanceThreshold) {
+		return len(nc.enumerateNeighborhood(grid, center, distanceThreshold)), nil
+	}
+	return singleNeighborhoodSize(grid, center, distanceThreshold), nil
+}
+
+// SaturatesStrip reports whether source's neighborhood at the threshold already holds every
+// unblocked cell of the grid, in O(1). It is meant for the 1xN and Nx1 strips where this
+// happens soonest, but answers correctly for any grid. It is false for a nil grid, a
+// negative threshold, or a source outside the grid.
+func (nc *NeighborhoodCalculator) SaturatesStrip(grid *Grid, source Position, distanceThreshold int) bool {
+	if grid == nil || distanceThreshold < 0 || !nc.boundaryHandler.IsWithinBounds(source, grid) {
+		return false
+	}
+	return distanceThreshold >= nc.farthestDistance(grid, source)
+}
+
+// farthestDistance returns the largest distance from center to any grid cell under the
+// calculator's topology. A wrapped axis of extent e is never more than e/2 away.
+func (nc *NeighborhoodCalculator) farthestDistance(grid *Grid, center Position) int {
+	rowReach := max(center.Row, grid.Height-1-center.Row)
+	if nc.topology.WrapRows {
+		rowReach = grid.Height / 2
+	}
+	colReach := max(center.Column, grid.Width-1-center.Column)
+	if nc.topology.WrapColumns {
+		colReach = grid.Width / 2
+	}
+	return rowReach + colReach
+}
+
+// closedFormApplies reports whether singleNeighborhoodSize is exact for grid and threshold
+func (nc *NeighborhoodCalculator) closedFormApplies(grid *Grid, distanceThreshold int) bool {
+	return grid.BlockedCellCount() == 0 && nc.topology == (Topology{}) && distanceThreshold <= maxClosedFormThreshold
+}
+
+// singleNeighborhoodSize counts the diamond of radius N around an in-bounds center by
+// inclusion-exclusion: the full 2N^2+2N+1 cells, minus the triangle cut off past each
+// edge, plus the corner pieces cut off by two adjacent edges at once. Opposite edges can
+// never cut the same cell, so no higher-order terms are needed.
+func singleNeighborhoodSize(grid *Grid, center Position, distanceThreshold int) int {
+	n := distanceThreshold
+	// Distance from center to the last row or column on each side
+	top, bottom := grid.Height-1-center.Row, center.Row
+	left, right := center.Column, grid.Width-1-center.Column
+
+	// cut counts the cells more than edge steps away along one axis: (N-edge)^2
+	cut := func(edge int) int {
+		excess := max(0, n-edge)
+		return excess * excess
+	}
+	// corner counts the cells beyond two adjacent edges: a triangle with side N-a-b-1
+	corner := func(a, b int) int {
+		side := max(0, n-a-b-1)
+		return side * (side + 1) / 2
+	}
+
+	count := 2*n*n + 2*n + 1
+	count -= cut(top) + cut(bottom) + cut(left) + cut(right)
+	count += corner(top, left) + corner(top, right) + corner(bottom, left) + corner(bottom, right)
+	return count
+}
+
+// RoundMode selects how a float radius is converted to an integer distance threshold
+type RoundMode int
+
+const (
+	// Floor rounds the radius down, so only cells fully within the radius count
+	Floor RoundMode = iota
+	// Round rounds the radius to the nearest integer, halves away from zero
+	Round
+	// Ceil rounds the radius up, so any cell the radius reaches counts
+	Ceil
+)
+
+// CountNeighborhoodCellsFloat counts neighborhood cells for a float radius, converting it
+// to an integer Manhattan threshold with the given rounding mode. A radius that is NaN,
+// infinite, or out of int range yields an InvalidRadiusError; one that rounds to a negative
+// threshold yields an InvalidDistanceThresholdError.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsFloat(grid *Grid, radius float64, mode RoundMode) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+
+	var rounded float64
+	switch mode {
+	case Floor:
+		rounded = math.Floor(radius)
+	case Round:
+		rounded = math.Round(radius)
+	case Ceil:
+		rounded = math.Ceil(radius)
+	default:
+		return 0, &InvalidRoundModeError{Mode: mode}
+	}
+	// -float64(math.MinInt) is exactly 2^(intSize-1), the first value past math.MaxInt
+	if math.IsNaN(rounded) || rounded < float64(math.MinInt) || rounded >= -float64(math.MinInt) {
+		return 0, &InvalidRadiusError{Radius: radius}
+	}
+	return nc.CountNeighborhoodCells(grid, int(rounded))
+}
+
+// GetNeighborhoodCells returns the set of all unique cells in neighborhoods.
+// It does not apply the WithMaxCells limit; use CollectNeighborhoodCells for that.
+func (nc *NeighborhoodCalculator) GetNeighborhoodCells(gl GridLike, distanceThreshold int) map[Position]bool {
+	grid, err := resolveGrid(gl)
+	if err != nil {
+		return make(map[Position]bool)
+	}
+	allCells, _ := nc.collectNeighborhoodCells(grid, distanceThreshold, 0)
+	return allCells
+}
+
+// CollectNeighborhoodCells returns the set of all unique cells in neighborhoods, or a
+// ResultTooLargeError as soon as the union would exceed the WithMaxCells limit
+func (nc *NeighborhoodCalculator) CollectNeighborhoodCells(gl GridLike, distanceThreshold int) (map[Position]bool, error) {
+	grid, err := resolveGrid(gl)
+	if err != nil {
+		return nil, err
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	return nc.collectNeighborhoodCells(grid, distanceThreshold, nc.maxCells)
+}
+
+// collectNeighborhoodCells builds the neighborhood union, stopping once it holds more
+// than limit cells (limit 0 means unlimited)
+func (nc *NeighborhoodCalculator) collectNeighborhoodCells(grid *Grid, distanceThreshold, limit int) (map[Position]bool, error) {
+	if grid == nil {
+		return make(map[Position]bool), ErrNilGrid
+	}
 	allCells := make(map[Position]bool)
 
 	// Handle empty positive cells case
 	if len(grid.PositiveCells) == 0 {
-		return allCells
+		return allCells, nil
 	}
+	excluded := grid.excludedCells()
 
 	// Optimization 1: Early termination - if distance threshold exceeds grid dimensions,
 	// return all grid cells
-	maxPossibleDistance := (grid.Height - 1) + (grid.Width - 1)
-	if distanceThreshold >= maxPossibleDistance {
+	if distanceThreshold >= grid.MaxManhattanDistance() {
+		if limit > 0 && grid.CellCount()-grid.BlockedCellCount()-len(excluded) > limit {
+			return nil, &ResultTooLargeError{Limit: limit}
+		}
 		for row := 0; row < grid.Height; row++ {
 			for col := 0; col < grid.Width; col++ {
-				allCells[Position{Row: row, Column: col}] = true
+				pos := Position{Row: row, Column: col}
+				if !grid.IsBlocked(pos) && !excluded[pos] {
+					allCells[pos] = true
+				}
 			}
 		}
-		return allCells
+		return allCells, nil
 	}
 
 	// For each positive cell, enumerate its neighborhood and add to union
 	for _, positiveCell := range grid.PositiveCells {
 		neighborhood := nc.enumerateNeighborhood(grid, positiveCell, distanceThreshold)
-		// Union operation
+		// Union operation, leaving out excluded cells
 		for pos := range neighborhood {
-			allCells[pos] = true
+			if !excluded[pos] {
+				allCells[pos] = true
+			}
+		}
+		if limit > 0 && len(allCells) > limit {
+			return nil, &ResultTooLargeError{Limit: limit}
 		}
 	}
 
-	return allCells
+	return allCells, nil
 }
 
 // enumerateNeighborhood enumerates all cells within Manhattan distance N from center
 func (nc *NeighborhoodCalculator) enumerateNeighborhood(grid *Grid, center Position, distanceThreshold int) map[Position]bool {
 	neighborhood := make(map[Position]bool)
+	if grid == nil {
+		return neighborhood
+	}
+	nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+		neighborhood[pos] = true
+		return true
+	})
+	return neighborhood
+}
+
+// scanNeighborhood calls visit for every unblocked in-bounds cell within Manhattan
+// distance N from center, by increasing delta-row and then increasing delta-column, until
+// visit returns false. Each cell is visited once, even when a wrapped diamond overlaps itself.
+func (nc *NeighborhoodCalculator) scanNeighborhood(grid *Grid, center Position, distanceThreshold int, visit func(Position) bool) {
+	// No grid cell is farther than this from center, so larger thresholds change nothing
+	// and would overflow the bounds arithmetic below
+	distanceThreshold = min(distanceThreshold, Abs(center.Row)+Abs(center.Column)+grid.MaxManhattanDistance())
+	center, _ = nc.wrapPosition(grid, center)
 
 	// Optimization 2: Calculate actual row range considering grid boundaries
-	minRow := max(0, center.Row-distanceThreshold)
-	maxRow := min(grid.Height-1, center.Row+distanceThreshold)
+	minDeltaRow, maxDeltaRow := axisReach(center.Row, grid.Height, distanceThreshold, nc.topology.WrapRows)
 
 	// Iterate through the diamond shape
-	for row := minRow; row <= maxRow; row++ {
-		deltaRow := row - center.Row
+	for deltaRow := minDeltaRow; deltaRow <= maxDeltaRow; deltaRow++ {
+		row := center.Row + deltaRow
+		if nc.topology.WrapRows {
+			row = wrapCoordinate(row, grid.Height)
+		}
 		remainingDistance := distanceThreshold - Abs(deltaRow)
 
 		// Optimization 2: Calculate actual column range considering grid boundaries
-		minCol := max(0, center.Column-remainingDistance)
-		maxCol := min(grid.Width-1, center.Column+remainingDistance)
+		minDeltaCol, maxDeltaCol := axisReach(center.Column, grid.Width, remainingDistance, nc.topology.WrapColumns)
 
-		for col := minCol; col <= maxCol; col++ {
-			neighborhood[Position{Row: row, Column: col}] = true
+		for deltaCol := minDeltaCol; deltaCol <= maxDeltaCol; deltaCol++ {
+			col := center.Column + deltaCol
+			if nc.topology.WrapColumns {
+				col = wrapCoordinate(col, grid.Width)
+			}
+			pos := Position{Row: row, Column: col}
+			// Masked cells are within distance but never count as covered
+			if !grid.IsBlocked(pos) && !visit(pos) {
+				return
+			}
 		}
 	}
+}
 
-	return neighborhood
+// GetNeighborhoodCellsUnclipped returns the union of the full diamonds around every
+// positive cell, including cells that fall outside the grid. Blocked cells are left out but
+// the grid's Excluded cells are kept, so filtering the result with
+// BoundaryHandler.FilterValidPositions yields GetNeighborhoodCells only on grids without
+// exclusions; GetNeighborhoodCellsFiltered drops them as well. The diamonds are materialized
+// in full, so a threshold that is negative or above MaxThresholdRange yields an empty map.
+func (nc *NeighborhoodCalculator) GetNeighborhoodCellsUnclipped(grid *Grid, distanceThreshold int) map[Position]bool {
+	if grid == nil || checkThresholdRange(distanceThreshold) != nil {
+		return make(map[Position]bool)
+	}
+	return nc.unclippedNeighborhoods(grid, distanceThreshold, false)
+}
+
+// unclippedNeighborhoods unions the full diamonds around every positive cell. With
+// clampToGrid, each radius is cut to the farthest grid cell from its center, which keeps
+// every in-grid cell while bounding the work for huge thresholds.
+func (nc *NeighborhoodCalculator) unclippedNeighborhoods(grid *Grid, distanceThreshold int, clampToGrid bool) map[Position]bool {
+	allCells := make(map[Position]bool)
+	for _, center := range grid.PositiveCells {
+		radius := distanceThreshold
+		if clampToGrid {
+			radius = min(radius, Abs(center.Row)+Abs(center.Column)+grid.MaxManhattanDistance())
+		}
+		for deltaRow := -radius; deltaRow <= radius; deltaRow++ {
+			remainingDistance := radius - Abs(deltaRow)
+			for deltaCol := -remainingDistance; deltaCol <= remainingDistance; deltaCol++ {
+				pos := center.Translate(deltaRow, deltaCol)
+				if !grid.IsBlocked(pos) {
+					allCells[pos] = true
+				}
+			}
+		}
+	}
+	return allCells
+}
+
+// GetNeighborhoodCellsFiltered computes the neighborhood union by enumerating unclipped
+// diamonds and then discarding out-of-bounds and excluded cells. On the default bounded
+// topology it gives the same result as GetNeighborhoodCells, which clips analytically and
+// is faster near edges; like GetNeighborhoodCellsUnclipped, it never wraps. A negative
+// threshold yields an empty map.
+func (nc *NeighborhoodCalculator) GetNeighborhoodCellsFiltered(grid *Grid, distanceThreshold int) map[Position]bool {
+	if grid == nil || distanceThreshold < 0 {
+		return make(map[Position]bool)
+	}
+	filtered := nc.boundaryHandler.FilterValidPositions(nc.unclippedNeighborhoods(grid, distanceThreshold, true), grid)
+	for pos := range grid.excludedCells() {
+		delete(filtered, pos)
+	}
+	return filtered
+}
+
+// CountNeighborhoodCellsInfinite counts the union of the full diamonds around positives as
+// if the grid were unbounded. It sweeps the rows the diamonds span, merging each row's
+// column intervals, so the cost does not depend on any grid size.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsInfinite(positives []Position, distanceThreshold int) (int, error) {
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if len(positives) == 0 {
+		return 0, nil
+	}
+	sources := slices.Clone(positives)
+	slices.SortFunc(sources, comparePositions)
+	sources = slices.Compact(sources)
+
+	// sources[first:end] are the diamonds that reach the current row
+	count := 0
+	first, end := 0, 0
+	spans := make([][2]int, 0, len(sources))
+	for row := sources[0].Row - distanceThreshold; first < len(sources); row++ {
+		for end < len(sources) && sources[end].Row <= row+distanceThreshold {
+			end++
+		}
+		for first < end && sources[first].Row < row-distanceThreshold {
+			first++
+		}
+		if first == end {
+			if end == len(sources) {
+				break
+			}
+			// Skip the empty rows up to the next diamond
+			row = sources[end].Row - distanceThreshold - 1
+			continue
+		}
+
+		spans = spans[:0]
+		for _, center := range sources[first:end] {
+			reach := distanceThreshold - Abs(row-center.Row)
+			spans = append(spans, [2]int{center.Column - reach, center.Column + reach})
+		}
+		slices.SortFunc(spans, func(a, b [2]int) int { return cmp.Compare(a[0], b[0]) })
+		from, to := spans[0][0], spans[0][1]
+		for _, span := range spans[1:] {
+			if span[0] > to+1 {
+				count += to - from + 1
+				from, to = span[0], span[1]
+			} else {
+				to = max(to, span[1])
+			}
+		}
+		count += to - from + 1
+	}
+	return count, nil
 }
 
 // EnumerateNeighborhood is the exported version for testing
@@ -101,6 +537,20 @@ func (nc *NeighborhoodCalculator) EnumerateNeighborhood(grid *Grid, center Posit
 	return nc.enumerateNeighborhood(grid, center, distanceThreshold)
 }
 
+// EnumerateNeighborhoodOrdered returns the same cells as EnumerateNeighborhood in scan
+// order: increasing delta-row, then increasing delta-column
+func (nc *NeighborhoodCalculator) EnumerateNeighborhoodOrdered(grid *Grid, center Position, distanceThreshold int) []Position {
+	if grid == nil {
+		return nil
+	}
+	var cells []Position
+	nc.scanNeighborhood(grid, center, distanceThreshold, func(pos Position) bool {
+		cells = append(cells, pos)
+		return true
+	})
+	return cells
+}
+
 // Helper functions for min/max
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,491 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestWithMaxCellsRejectsLargeUnions(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+
+	// Scenario 4 covers exactly 22 cells, which is within a limit of 22
+	calculator := NewNeighborhoodCalculator(WithMaxCells(22))
+	if count, err := calculator.CountNeighborhoodCells(grid, 2); err != nil || count != 22 {
+		t.Errorf("Expected 22, got %d (err=%v)", count, err)
+	}
+
+	calculator = NewNeighborhoodCalculator(WithMaxCells(21))
+	_, err := calculator.CountNeighborhoodCells(grid, 2)
+	var tooLarge *ResultTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Limit != 21 {
+		t.Errorf("Expected ResultTooLargeError with limit 21, got %v", err)
+	}
+	if cells, err := calculator.CollectNeighborhoodCells(grid, 2); !errors.As(err, &tooLarge) || cells != nil {
+		t.Errorf("Expected ResultTooLargeError from CollectNeighborhoodCells, got %v", err)
+	}
+
+	// Saturating thresholds are rejected without enumerating
+	huge, _ := NewGrid(20000, 20000, []Position{{Row: 0, Column: 0}})
+	if _, err := NewNeighborhoodCalculator(WithMaxCells(1000)).CountNeighborhoodCells(huge, 1<<30); !errors.As(err, &tooLarge) {
+		t.Errorf("Expected ResultTooLargeError for saturating threshold, got %v", err)
+	}
+}
+
+func TestCollectNeighborhoodCellsWithoutLimit(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	cells, err := calculator.CollectNeighborhoodCells(grid, 3)
+	if err != nil || len(cells) != 25 {
+		t.Errorf("Expected 25 cells, got %d (err=%v)", len(cells), err)
+	}
+	if _, err := calculator.CollectNeighborhoodCells(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+}
+
+func TestRequirePositiveCells(t *testing.T) {
+	empty, _ := NewGrid(10, 10, []Position{})
+	if empty.HasPositiveCells() {
+		t.Error("Expected HasPositiveCells to be false")
+	}
+
+	// Default behavior matches Scenario 26
+	if count, err := NewNeighborhoodCalculator().CountNeighborhoodCells(empty, 3); err != nil || count != 0 {
+		t.Errorf("Expected 0 with no error, got %d (err=%v)", count, err)
+	}
+
+	strict := NewNeighborhoodCalculator(WithRequirePositiveCells())
+	_, err := strict.CountNeighborhoodCells(empty, 3)
+	var noSources *NoPositiveCellsError
+	if !errors.As(err, &noSources) {
+		t.Errorf("Expected NoPositiveCellsError, got %v", err)
+	}
+
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	if !grid.HasPositiveCells() {
+		t.Error("Expected HasPositiveCells to be true")
+	}
+	if count, err := strict.CountNeighborhoodCells(grid, 3); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+}
+
+func TestNilGridReturnsErrNilGrid(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	if _, err := calculator.CountNeighborhoodCells(nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CountNeighborhoodCells: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.CollectNeighborhoodCells(nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CollectNeighborhoodCells: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.ProbeCoverage(nil, Position{}, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("ProbeCoverage: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.CoverageRatioInWindow(nil, 3, 0, 0, 1, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CoverageRatioInWindow: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.CountWithExternalSources(nil, nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CountWithExternalSources: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.CountChamferNeighborhoodCells(nil, 1, 1, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CountChamferNeighborhoodCells: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.CountWeightedManhattanCells(nil, 1, 1, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("CountWeightedManhattanCells: expected ErrNilGrid, got %v", err)
+	}
+	if _, err := calculator.Count3DNeighborhoodCells(nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Count3DNeighborhoodCells: expected ErrNilGrid, got %v", err)
+	}
+	if err := calculator.RenderGeoJSON(nil, nil, 3); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("RenderGeoJSON: expected ErrNilGrid, got %v", err)
+	}
+
+	// Methods without an error return degrade to empty results instead of panicking
+	if cells := calculator.GetNeighborhoodCells(nil, 3); len(cells) != 0 {
+		t.Errorf("GetNeighborhoodCells: expected empty set, got %v", cells)
+	}
+	if cells := calculator.EnumerateNeighborhood(nil, Position{}, 3); len(cells) != 0 {
+		t.Errorf("EnumerateNeighborhood: expected empty set, got %v", cells)
+	}
+	if calculator.CoverageAtLeast(nil, 3, 1) {
+		t.Error("CoverageAtLeast: expected false")
+	}
+	if calculator.RowCoverage(nil, 3) != nil || calculator.ColumnCoverage(nil, 3) != nil || calculator.OverlappingPairs(nil, 3) != nil {
+		t.Error("Expected nil slices for nil grid")
+	}
+	if cells := calculator.GetUncoveredCells(nil, 3); len(cells) != 0 {
+		t.Errorf("GetUncoveredCells: expected empty set, got %v", cells)
+	}
+	calculator.ForEachCell(nil, 3, func(Position, bool) { t.Error("ForEachCell: unexpected visit") })
+	for range calculator.PerSourceNeighborhoods(nil, 3) {
+		t.Error("PerSourceNeighborhoods: unexpected yield")
+	}
+}
+
+func TestUnclippedNeighborhoodFilteredThroughBoundaryHandler(t *testing.T) {
+	// Scenario 2: the diamond spills two columns off the left edge
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 1}})
+	calculator := NewNeighborhoodCalculator()
+
+	unclipped := calculator.GetNeighborhoodCellsUnclipped(grid, 3)
+	if len(unclipped) != 25 {
+		t.Errorf("Expected 25 unclipped cells, got %d", len(unclipped))
+	}
+	if !unclipped[Position{Row: 5, Column: -2}] {
+		t.Error("Expected out-of-bounds cell (5,-2) in unclipped result")
+	}
+
+	filtered := NewBoundaryHandler().FilterValidPositions(unclipped, grid)
+	clipped := calculator.GetNeighborhoodCells(grid, 3)
+	if len(filtered) != 21 || len(clipped) != 21 {
+		t.Fatalf("Expected 21 cells, got filtered=%d clipped=%d", len(filtered), len(clipped))
+	}
+	for pos := range clipped {
+		if !filtered[pos] {
+			t.Errorf("Filtered result missing %v", pos)
+		}
+	}
+	if len(calculator.GetNeighborhoodCellsFiltered(grid, 3)) != 21 {
+		t.Error("GetNeighborhoodCellsFiltered should match GetNeighborhoodCells")
+	}
+}
+
+func TestUnclippedNeighborhoodThresholdLimits(t *testing.T) {
+	grid, _ := NewGrid(5, 5, []Position{{Row: 2, Column: 2}})
+	calculator := NewNeighborhoodCalculator()
+	for _, threshold := range []int{-1, MaxThresholdRange + 1, math.MaxInt} {
+		if cells := calculator.GetNeighborhoodCellsUnclipped(grid, threshold); len(cells) != 0 {
+			t.Errorf("Threshold %d: expected no unclipped cells, got %d", threshold, len(cells))
+		}
+	}
+	if cells := calculator.GetNeighborhoodCellsFiltered(grid, -1); len(cells) != 0 {
+		t.Errorf("Expected no filtered cells for a negative threshold, got %d", len(cells))
+	}
+
+	// Filtering only needs the in-grid part, so huge thresholds still finish
+	if cells := calculator.GetNeighborhoodCellsFiltered(grid, math.MaxInt); len(cells) != 25 {
+		t.Errorf("Expected all 25 cells, got %d", len(cells))
+	}
+
+	// Excluded cells are dropped as GetNeighborhoodCells drops them
+	grid.Excluded = []Position{{Row: 2, Column: 3}}
+	filtered := calculator.GetNeighborhoodCellsFiltered(grid, 1)
+	if len(filtered) != 4 || filtered[Position{Row: 2, Column: 3}] {
+		t.Errorf("Expected 4 cells without (2,3), got %v", filtered)
+	}
+}
+
+func TestCountNeighborhoodCellsFloatRoundsRadius(t *testing.T) {
+	// Scenario 1: single center source; thresholds 1, 2, and 3 cover 5, 13, and 25 cells
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	tests := []struct {
+		radius   float64
+		mode     RoundMode
+		expected int
+	}{
+		{2.5, Floor, 13},
+		{2.5, Round, 25},
+		{2.5, Ceil, 25},
+		{2.4, Round, 13},
+		{2.0, Ceil, 13},
+		{1.1, Ceil, 13},
+		{1.9, Floor, 5},
+		{-0.4, Round, 1},
+	}
+	for _, tt := range tests {
+		count, err := calculator.CountNeighborhoodCellsFloat(grid, tt.radius, tt.mode)
+		if err != nil || count != tt.expected {
+			t.Errorf("radius %v mode %d: expected %d, got %d (err=%v)", tt.radius, tt.mode, tt.expected, count, err)
+		}
+	}
+
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountNeighborhoodCellsFloat(grid, -0.5, Round); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError for -0.5 rounded, got %v", err)
+	}
+	var radiusErr *InvalidRadiusError
+	for _, radius := range []float64{math.NaN(), math.Inf(1), 1e300, -float64(math.MinInt)} {
+		if _, err := calculator.CountNeighborhoodCellsFloat(grid, radius, Floor); !errors.As(err, &radiusErr) {
+			t.Errorf("Expected InvalidRadiusError for %v, got %v", radius, err)
+		}
+	}
+	// Radii beyond the int32 range are fine as long as they fit an int
+	if count, err := calculator.CountNeighborhoodCellsFloat(grid, float64(math.MaxInt/2), Floor); err != nil || count != 121 {
+		t.Errorf("Expected 121 for a huge in-range radius, got %d (err=%v)", count, err)
+	}
+	var modeErr *InvalidRoundModeError
+	if _, err := calculator.CountNeighborhoodCellsFloat(grid, 1, RoundMode(7)); !errors.As(err, &modeErr) {
+		t.Errorf("Expected InvalidRoundModeError, got %v", err)
+	}
+}
+
+func TestCountNeighborhoodCellsInfinite(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 2: the unclipped diamond at the left edge has 2N^2+2N+1 = 25 cells
+	if count, err := calculator.CountNeighborhoodCellsInfinite([]Position{{Row: 5, Column: 1}}, 3); err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+	// Far-apart diamonds add up, and duplicates count once
+	sources := []Position{{Row: 0, Column: 0}, {Row: 1000, Column: -1000}, {Row: 0, Column: 0}}
+	if count, _ := calculator.CountNeighborhoodCellsInfinite(sources, 2); count != 26 {
+		t.Errorf("Expected 26, got %d", count)
+	}
+	if count, _ := calculator.CountNeighborhoodCellsInfinite(nil, 2); count != 0 {
+		t.Errorf("Expected 0 for no sources, got %d", count)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountNeighborhoodCellsInfinite(sources, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestFilteredMatchesClippedWithExcludedCells(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		numExcluded := rapid.IntRange(1, 6).Draw(t, "numExcluded")
+		for i := 0; i < numExcluded; i++ {
+			grid.Excluded = append(grid.Excluded, Position{
+				Row:    rapid.IntRange(0, grid.Height-1).Draw(t, "excluded_row"),
+				Column: rapid.IntRange(0, grid.Width-1).Draw(t, "excluded_col"),
+			})
+		}
+		distanceThreshold := rapid.IntRange(0, 20).Draw(t, "distanceThreshold")
+
+		calculator := NewNeighborhoodCalculator()
+		filtered := calculator.GetNeighborhoodCellsFiltered(grid, distanceThreshold)
+		clipped := calculator.GetNeighborhoodCells(grid, distanceThreshold)
+		if !CoverageEqual(filtered, clipped) {
+			missing, extra := DiffCoverage(clipped, filtered)
+			t.Fatalf("Filtered differs from clipped: missing %v, extra %v", missing, extra)
+		}
+	})
+}
+
+func TestCountNeighborhoodCellsInfiniteMatchesUnclipped(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 6)
+		threshold := rapid.IntRange(0, 8).Draw(t, "threshold")
+
+		calculator := NewNeighborhoodCalculator()
+		count, err := calculator.CountNeighborhoodCellsInfinite(grid.PositiveCells, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if expected := len(calculator.GetNeighborhoodCellsUnclipped(grid, threshold)); count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestEnumerateNeighborhoodOrdered(t *testing.T) {
+	// Scenario 2: diamond clipped at the left edge
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 1}})
+	calculator := NewNeighborhoodCalculator()
+
+	ordered := calculator.EnumerateNeighborhoodOrdered(grid, Position{Row: 5, Column: 1}, 1)
+	expected := []Position{{Row: 4, Column: 1}, {Row: 5, Column: 0}, {Row: 5, Column: 1}, {Row: 5, Column: 2}, {Row: 6, Column: 1}}
+	if !slices.Equal(ordered, expected) {
+		t.Errorf("Expected %v, got %v", expected, ordered)
+	}
+
+	ordered = calculator.EnumerateNeighborhoodOrdered(grid, Position{Row: 5, Column: 1}, 3)
+	set := calculator.EnumerateNeighborhood(grid, Position{Row: 5, Column: 1}, 3)
+	if len(ordered) != len(set) {
+		t.Fatalf("Expected %d cells, got %d", len(set), len(ordered))
+	}
+	for i, pos := range ordered {
+		if !set[pos] {
+			t.Errorf("Unexpected cell %v", pos)
+		}
+		if i > 0 {
+			prev := ordered[i-1]
+			if prev.Row > pos.Row || (prev.Row == pos.Row && prev.Column >= pos.Column) {
+				t.Errorf("Cells out of scan order: %v before %v", prev, pos)
+			}
+		}
+	}
+
+	if calculator.EnumerateNeighborhoodOrdered(nil, Position{}, 3) != nil {
+		t.Error("Expected nil for nil grid")
+	}
+}
+
+func TestMaxIntThresholdSaturates(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 10, Column: 10}})
+	calculator := NewNeighborhoodCalculator()
+
+	if count, err := calculator.CountNeighborhoodCells(grid, math.MaxInt); err != nil || count != 121 {
+		t.Errorf("CountNeighborhoodCells: expected 121, got %d (err=%v)", count, err)
+	}
+	if cells := calculator.EnumerateNeighborhood(grid, Position{Row: 3, Column: 3}, math.MaxInt); len(cells) != 121 {
+		t.Errorf("EnumerateNeighborhood: expected 121, got %d", len(cells))
+	}
+	if count, _ := calculator.CountWithExternalSources(grid, []Position{{Row: 50, Column: -50}}, math.MaxInt); count != 121 {
+		t.Errorf("CountWithExternalSources: expected 121, got %d", count)
+	}
+	if pairs := calculator.OverlappingPairs(grid, math.MaxInt); len(pairs) != 1 {
+		t.Errorf("OverlappingPairs: expected 1 pair, got %v", pairs)
+	}
+
+	grid.AddBlockedRect(0, 0, 0, 10)
+	if count, _ := calculator.CountNeighborhoodCells(grid, math.MaxInt); count != 110 {
+		t.Errorf("Expected 110 unblocked cells, got %d", count)
+	}
+}
+
+func TestCountSingleNeighborhoodScenarios(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+	tests := []struct {
+		name                        string
+		height, width               int
+		center                      Position
+		distanceThreshold, expected int
+	}{
+		{"Scenario 1 center", 11, 11, Position{Row: 5, Column: 5}, 3, 25},
+		{"Scenario 2 left edge", 11, 11, Position{Row: 5, Column: 1}, 3, 21},
+		{"Scenario 3 corner", 11, 11, Position{Row: 0, Column: 0}, 3, 10},
+		{"single cell grid", 1, 1, Position{}, 0, 1},
+		{"one row", 1, 10, Position{Row: 0, Column: 2}, 4, 7},
+		{"narrow column both edges", 10, 3, Position{Row: 5, Column: 1}, 3, 17},
+	}
+	for _, tt := range tests {
+		grid, _ := NewGrid(tt.height, tt.width, []Position{tt.center})
+		count, err := calculator.CountSingleNeighborhood(grid, tt.center, tt.distanceThreshold)
+		if err != nil || count != tt.expected {
+			t.Errorf("%s: expected %d, got %d (err=%v)", tt.name, tt.expected, count, err)
+		}
+		if viaCount, _ := calculator.CountNeighborhoodCells(grid, tt.distanceThreshold); viaCount != tt.expected {
+			t.Errorf("%s: CountNeighborhoodCells expected %d, got %d", tt.name, tt.expected, viaCount)
+		}
+	}
+}
+
+func TestCountSingleNeighborhoodMatchesEnumeration(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 25).Draw(t, "height")
+		width := rapid.IntRange(1, 25).Draw(t, "width")
+		center := Position{
+			Row:    rapid.IntRange(0, height-1).Draw(t, "row"),
+			Column: rapid.IntRange(0, width-1).Draw(t, "col"),
+		}
+		threshold := rapid.IntRange(0, 50).Draw(t, "threshold")
+		grid, _ := NewGrid(height, width, []Position{center})
+		if rapid.Bool().Draw(t, "masked") {
+			// Rejected when the rectangle would cover the center, leaving the grid unmasked
+			grid.AddBlockedRect(0, 0, height/2, width/2)
+		}
+
+		calculator := NewNeighborhoodCalculator()
+		count, err := calculator.CountSingleNeighborhood(grid, center, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if expected := len(calculator.EnumerateNeighborhood(grid, center, threshold)); count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+	})
+}
+
+func TestMustCountNeighborhoodCells(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+	if count := calculator.MustCountNeighborhoodCells(grid, 2); count != 22 {
+		t.Errorf("Expected 22, got %d", count)
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		var thresholdErr *InvalidDistanceThresholdError
+		if !ok || !errors.As(err, &thresholdErr) {
+			t.Errorf("Expected a panic with InvalidDistanceThresholdError, got %v", err)
+		}
+	}()
+	calculator.MustCountNeighborhoodCells(grid, -1)
+}
+
+func TestCountSingleNeighborhoodSaturatesHugeGrid(t *testing.T) {
+	// Enumerating this grid would take minutes; a threshold past the farthest cell must not
+	grid, err := NewGrid(60000, 60000, []Position{{Row: 100, Column: 30000}})
+	if err != nil {
+		t.Skipf("60000x60000 grid needs a 64-bit int: %v", err)
+	}
+	calculator := NewNeighborhoodCalculator()
+	for _, threshold := range []int{59899 + 30000, 1 << 30, math.MaxInt} {
+		if size, err := calculator.CountSingleNeighborhood(grid, grid.PositiveCells[0], threshold); err != nil || size != grid.CellCount() {
+			t.Errorf("N=%d: expected %d, got %d (err=%v)", threshold, grid.CellCount(), size, err)
+		}
+	}
+
+	// Blocked cells are still left out of a saturated neighborhood
+	small, _ := NewGrid(5, 5, []Position{{Row: 0, Column: 0}})
+	small.AddBlockedRect(4, 4, 4, 4)
+	if size, _ := calculator.CountSingleNeighborhood(small, Position{Row: 0, Column: 0}, 8); size != 24 {
+		t.Errorf("Expected 24, got %d", size)
+	}
+}
+
+func TestSaturatesStrip(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 15 style 1x10 strip: from column 2 the far end is 7 away
+	strip, _ := NewGrid(1, 10, []Position{{Row: 0, Column: 2}})
+	source := Position{Row: 0, Column: 2}
+	if calculator.SaturatesStrip(strip, source, 6) || !calculator.SaturatesStrip(strip, source, 7) {
+		t.Error("Expected saturation to start at N=7")
+	}
+	column, _ := NewGrid(10, 1, []Position{{Row: 9, Column: 0}})
+	if !calculator.SaturatesStrip(column, Position{Row: 9, Column: 0}, 9) || calculator.SaturatesStrip(column, Position{Row: 9, Column: 0}, 8) {
+		t.Error("Expected a 10x1 strip from its end to saturate at exactly N=9")
+	}
+
+	// The answer agrees with the enumerated neighborhood
+	for n := 0; n <= 9; n++ {
+		size, _ := calculator.CountSingleNeighborhood(strip, source, n)
+		if calculator.SaturatesStrip(strip, source, n) != (size == 10) {
+			t.Errorf("N=%d: SaturatesStrip disagrees with neighborhood size %d", n, size)
+		}
+	}
+
+	// A wrapped strip is never more than half its length away
+	ring := NewNeighborhoodCalculator(WithTopology(Topology{WrapColumns: true}))
+	if !ring.SaturatesStrip(strip, source, 5) || ring.SaturatesStrip(strip, source, 4) {
+		t.Error("Expected a wrapped 1x10 strip to saturate at N=5")
+	}
+
+	if calculator.SaturatesStrip(strip, Position{Row: 1, Column: 0}, 20) || calculator.SaturatesStrip(strip, source, -1) || calculator.SaturatesStrip(nil, source, 20) {
+		t.Error("Expected false for an out-of-bounds source, negative threshold, or nil grid")
+	}
+}
+
+func TestCountNeighborhoodCellsDetailed(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	calculator := NewNeighborhoodCalculator()
+
+	count, effective, err := calculator.CountNeighborhoodCellsDetailed(grid, 2)
+	if err != nil || count != 13 || effective != 2 {
+		t.Errorf("Expected (13, 2), got (%d, %d) (err=%v)", count, effective, err)
+	}
+	// Past the 20-step grid diagonal the threshold is clamped and the count saturates
+	count, effective, _ = calculator.CountNeighborhoodCellsDetailed(grid, 1000)
+	if count != 121 || effective != 20 {
+		t.Errorf("Expected (121, 20), got (%d, %d)", count, effective)
+	}
+
+	if _, _, err := calculator.CountNeighborhoodCellsDetailed(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, _, err := calculator.CountNeighborhoodCellsDetailed(nil, 2); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
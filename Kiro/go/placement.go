@@ -0,0 +1,131 @@
+package gridneighborhoods
+
+import "math/rand/v2"
+
+// MarginalGain returns how many cells a source placed at candidate would add to the
+// existing neighborhood union, i.e. the cells of its clipped neighborhood that no positive
+// cell already covers. Excluded cells never add to the gain.
+func (nc *NeighborhoodCalculator) MarginalGain(grid *Grid, candidate Position, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if !nc.boundaryHandler.IsWithinBounds(candidate, grid) {
+		return 0, &PositionOutOfBoundsError{Position: candidate, Height: grid.Height, Width: grid.Width}
+	}
+	return nc.marginalGain(grid, nc.GetNeighborhoodCells(grid, distanceThreshold), grid.excludedCells(), candidate, distanceThreshold), nil
+}
+
+// marginalGain counts the cells of candidate's neighborhood missing from covered, leaving
+// out the excluded ones
+func (nc *NeighborhoodCalculator) marginalGain(grid *Grid, covered, excluded map[Position]bool, candidate Position, distanceThreshold int) int {
+	gain := 0
+	nc.scanNeighborhood(grid, candidate, distanceThreshold, func(pos Position) bool {
+		if !covered[pos] && !excluded[pos] {
+			gain++
+		}
+		return true
+	})
+	return gain
+}
+
+// CandidateCells returns the unblocked cells outside the neighborhood union, sorted by row,
+// then column, for use as GreedyPlacementFrom candidates. Positive cells are left out even
+// when excluded from the union. It returns nil for a nil grid or a negative threshold.
+func (nc *NeighborhoodCalculator) CandidateCells(grid *Grid, distanceThreshold int) []Position {
+	if grid == nil || distanceThreshold < 0 {
+		return nil
+	}
+	var candidates []Position
+	nc.ForEachCell(grid, distanceThreshold, func(pos Position, covered bool) {
+		if !covered && !grid.IsBlocked(pos) && !grid.IsPositive(pos) {
+			candidates = append(candidates, pos)
+		}
+	})
+	return candidates
+}
+
+// GreedyPlacement picks up to k new source positions among the grid's unblocked cells
+// that are not already positive, each time taking the cell with the largest marginal gain.
+// Ties go to the lowest row, then column, unless WithTieBreakSeed is set, in which case a
+// tied candidate is drawn at random from that seed. It returns the chosen positions and the
+// resulting coverage, including the existing positive cells and leaving out excluded
+// cells as CountNeighborhoodCells does. Fewer than k positions are
+// returned once no candidate adds coverage. The grid itself is not modified.
+func (nc *NeighborhoodCalculator) GreedyPlacement(grid *Grid, k, distanceThreshold int) ([]Position, int, error) {
+	if grid == nil {
+		return nil, 0, ErrNilGrid
+	}
+	var candidates []Position
+	for row := 0; row < grid.Height; row++ {
+		for col := 0; col < grid.Width; col++ {
+			pos := Position{Row: row, Column: col}
+			if !grid.IsPositive(pos) && !grid.IsBlocked(pos) {
+				candidates = append(candidates, pos)
+			}
+		}
+	}
+	return nc.GreedyPlacementFrom(grid, candidates, k, distanceThreshold)
+}
+
+// GreedyPlacementFrom is GreedyPlacement restricted to the given candidate positions, which
+// must lie within the grid. Ties are broken as in GreedyPlacement.
+func (nc *NeighborhoodCalculator) GreedyPlacementFrom(grid *Grid, candidates []Position, k, distanceThreshold int) ([]Position, int, error) {
+	if grid == nil {
+		return nil, 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if k < 0 {
+		return nil, 0, &InvalidPlacementCountError{K: k}
+	}
+	for _, candidate := range candidates {
+		if !nc.boundaryHandler.IsWithinBounds(candidate, grid) {
+			return nil, 0, &PositionOutOfBoundsError{Position: candidate, Height: grid.Height, Width: grid.Width}
+		}
+	}
+
+	// Each call restarts the seeded sequence, so equal seeds reproduce equal placements
+	var rng *rand.Rand
+	if nc.tieBreakSeed != nil {
+		rng = rand.New(rand.NewPCG(*nc.tieBreakSeed, 0))
+	}
+
+	covered := nc.GetNeighborhoodCells(grid, distanceThreshold)
+	excluded := grid.excludedCells()
+	var chosen []Position
+	for len(chosen) < k {
+		best, bestGain, ties := -1, 0, 0
+		for i, candidate := range candidates {
+			gain := nc.marginalGain(grid, covered, excluded, candidate, distanceThreshold)
+			switch {
+			case gain > bestGain:
+				best, bestGain, ties = i, gain, 1
+			case gain == bestGain && gain > 0:
+				// Reservoir sampling keeps each tied candidate with equal probability
+				ties++
+				if rng != nil {
+					if rng.IntN(ties) == 0 {
+						best = i
+					}
+				} else if comparePositions(candidate, candidates[best]) < 0 {
+					best = i
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+		chosen = append(chosen, candidates[best])
+		nc.scanNeighborhood(grid, candidates[best], distanceThreshold, func(pos Position) bool {
+			if !excluded[pos] {
+				covered[pos] = true
+			}
+			return true
+		})
+	}
+	return chosen, len(covered), nil
+}
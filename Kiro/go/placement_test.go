@@ -0,0 +1,206 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestMarginalGain(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4: adding (4,5) next to (3,3) with N=2 brings the union from 13 to 22
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}})
+	if gain, err := calculator.MarginalGain(grid, Position{Row: 4, Column: 5}, 2); err != nil || gain != 9 {
+		t.Errorf("Expected 9, got %d (err=%v)", gain, err)
+	}
+	// An existing source gains nothing
+	if gain, _ := calculator.MarginalGain(grid, Position{Row: 3, Column: 3}, 2); gain != 0 {
+		t.Errorf("Expected 0, got %d", gain)
+	}
+	// Without sources the gain is the clipped neighborhood size (Scenario 3 corner)
+	empty, _ := NewGrid(11, 11, nil)
+	if gain, _ := calculator.MarginalGain(empty, Position{Row: 0, Column: 0}, 2); gain != 6 {
+		t.Errorf("Expected 6, got %d", gain)
+	}
+
+	var boundsErr *PositionOutOfBoundsError
+	if _, err := calculator.MarginalGain(grid, Position{Row: -1, Column: 0}, 2); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.MarginalGain(grid, Position{}, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
+
+func TestMarginalGainMatchesUnionDifference(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 4)
+		threshold := rapid.IntRange(0, 6).Draw(t, "threshold")
+		candidate := Position{
+			Row:    rapid.IntRange(0, grid.Height-1).Draw(t, "candidate_row"),
+			Column: rapid.IntRange(0, grid.Width-1).Draw(t, "candidate_col"),
+		}
+
+		calculator := NewNeighborhoodCalculator()
+		gain, err := calculator.MarginalGain(grid, candidate, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		before, _ := calculator.CountNeighborhoodCells(grid, threshold)
+		extended, _ := NewGrid(grid.Height, grid.Width, append(append([]Position{}, grid.PositiveCells...), candidate))
+		after, _ := calculator.CountNeighborhoodCells(extended, threshold)
+		if gain != after-before {
+			t.Fatalf("Expected gain %d, got %d", after-before, gain)
+		}
+	})
+}
+
+func TestGreedyPlacement(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Two N=1 diamonds cover a 1x6 strip; the greedy picks the first full-gain cell, then the next
+	grid, _ := NewGrid(1, 6, nil)
+	chosen, coverage, err := calculator.GreedyPlacement(grid, 3, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := []Position{{Row: 0, Column: 1}, {Row: 0, Column: 4}}
+	if len(chosen) != 2 || chosen[0] != expected[0] || chosen[1] != expected[1] {
+		t.Errorf("Expected %v, got %v", expected, chosen)
+	}
+	if coverage != 6 {
+		t.Errorf("Expected coverage 6, got %d", coverage)
+	}
+
+	// Existing sources count toward coverage and are never chosen again
+	grid, _ = NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	chosen, coverage, _ = calculator.GreedyPlacement(grid, 1, 10)
+	if len(chosen) != 0 || coverage != 121 {
+		t.Errorf("Expected nothing to add to full coverage, got %v with %d", chosen, coverage)
+	}
+	chosen, coverage, _ = calculator.GreedyPlacement(grid, 0, 2)
+	if len(chosen) != 0 || coverage != 13 {
+		t.Errorf("Expected k=0 to keep coverage 13, got %v with %d", chosen, coverage)
+	}
+
+	var countErr *InvalidPlacementCountError
+	if _, _, err := calculator.GreedyPlacement(grid, -1, 2); !errors.As(err, &countErr) {
+		t.Errorf("Expected InvalidPlacementCountError, got %v", err)
+	}
+	var boundsErr *PositionOutOfBoundsError
+	if _, _, err := calculator.GreedyPlacementFrom(grid, []Position{{Row: 11, Column: 0}}, 1, 2); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+}
+
+func TestGreedyPlacementFromCandidates(t *testing.T) {
+	grid, _ := NewGrid(11, 11, nil)
+	calculator := NewNeighborhoodCalculator()
+
+	// The corner candidate is clipped to 6 cells with N=2; the centered one covers 13
+	candidates := []Position{{Row: 0, Column: 0}, {Row: 5, Column: 5}}
+	chosen, coverage, err := calculator.GreedyPlacementFrom(grid, candidates, 1, 2)
+	if err != nil || len(chosen) != 1 || chosen[0] != candidates[1] || coverage != 13 {
+		t.Errorf("Expected [(5,5)] with 13, got %v with %d (err=%v)", chosen, coverage, err)
+	}
+	chosen, coverage, _ = calculator.GreedyPlacementFrom(grid, candidates, 2, 2)
+	if len(chosen) != 2 || coverage != 19 {
+		t.Errorf("Expected both candidates with 19, got %v with %d", chosen, coverage)
+	}
+}
+
+func TestGreedyPlacementCoverageIsConsistent(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 10, 3)
+		k := rapid.IntRange(0, 4).Draw(t, "k")
+		threshold := rapid.IntRange(0, 4).Draw(t, "threshold")
+
+		calculator := NewNeighborhoodCalculator()
+		chosen, coverage, err := calculator.GreedyPlacement(grid, k, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(chosen) > k {
+			t.Fatalf("Chose %d positions for k=%d", len(chosen), k)
+		}
+		extended, _ := NewGrid(grid.Height, grid.Width, append(append([]Position{}, grid.PositiveCells...), chosen...))
+		if expected, _ := calculator.CountNeighborhoodCells(extended, threshold); coverage != expected {
+			t.Fatalf("Expected coverage %d, got %d", expected, coverage)
+		}
+	})
+}
+
+func TestGreedyPlacementTieBreaking(t *testing.T) {
+	// With N=0 every empty cell gains exactly one, so every choice is a tie
+	grid, _ := NewGrid(6, 6, nil)
+
+	chosen, _, _ := NewNeighborhoodCalculator().GreedyPlacement(grid, 3, 0)
+	expected := []Position{{Row: 0, Column: 0}, {Row: 0, Column: 1}, {Row: 0, Column: 2}}
+	if !slices.Equal(chosen, expected) {
+		t.Errorf("Expected lowest row-then-column %v, got %v", expected, chosen)
+	}
+
+	// Candidate order does not affect the default tie-break
+	reversed := []Position{{Row: 5, Column: 5}, {Row: 2, Column: 3}, {Row: 2, Column: 1}}
+	chosen, _, _ = NewNeighborhoodCalculator().GreedyPlacementFrom(grid, reversed, 1, 0)
+	if !slices.Equal(chosen, []Position{{Row: 2, Column: 1}}) {
+		t.Errorf("Expected (2,1), got %v", chosen)
+	}
+
+	first, _, _ := NewNeighborhoodCalculator(WithTieBreakSeed(42)).GreedyPlacement(grid, 5, 0)
+	again, _, _ := NewNeighborhoodCalculator(WithTieBreakSeed(42)).GreedyPlacement(grid, 5, 0)
+	if !slices.Equal(first, again) {
+		t.Errorf("Expected equal seeds to reproduce %v, got %v", first, again)
+	}
+	differs := false
+	for seed := uint64(0); seed < 10 && !differs; seed++ {
+		other, _, _ := NewNeighborhoodCalculator(WithTieBreakSeed(seed)).GreedyPlacement(grid, 5, 0)
+		differs = !slices.Equal(first, other)
+	}
+	if !differs {
+		t.Error("Expected some seed to choose differently")
+	}
+}
+
+func TestCandidateCells(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// A corner source at N=2 in a 3x3 grid leaves only the far corner triangle uncovered
+	grid, _ := NewGrid(3, 3, []Position{{Row: 0, Column: 0}})
+	expected := []Position{{Row: 1, Column: 2}, {Row: 2, Column: 1}, {Row: 2, Column: 2}}
+	if candidates := calculator.CandidateCells(grid, 2); !slices.Equal(candidates, expected) {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+
+	// Blocked cells are not candidates
+	grid.AddBlockedRect(2, 2, 2, 2)
+	expected = expected[:2]
+	if candidates := calculator.CandidateCells(grid, 2); !slices.Equal(candidates, expected) {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+
+	// An excluded source is uncovered but still not a candidate
+	grid, _ = NewGrid(1, 3, []Position{{Row: 0, Column: 0}})
+	grid.Excluded = []Position{{Row: 0, Column: 0}}
+	expected = []Position{{Row: 0, Column: 2}}
+	if candidates := calculator.CandidateCells(grid, 1); !slices.Equal(candidates, expected) {
+		t.Errorf("Expected %v, got %v", expected, candidates)
+	}
+
+	// Candidates feed straight into GreedyPlacementFrom
+	grid, _ = NewGrid(11, 11, []Position{{Row: 5, Column: 5}})
+	chosen, coverage, err := calculator.GreedyPlacementFrom(grid, calculator.CandidateCells(grid, 2), 1, 2)
+	if err != nil || len(chosen) != 1 || coverage != 26 {
+		t.Errorf("Expected one disjoint placement covering 26, got %v with %d (err=%v)", chosen, coverage, err)
+	}
+
+	if calculator.CandidateCells(nil, 2) != nil || calculator.CandidateCells(grid, -1) != nil {
+		t.Error("Expected nil for a nil grid or negative threshold")
+	}
+}
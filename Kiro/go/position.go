@@ -1,11 +1,52 @@
 package gridneighborhoods
 
-// Position represents a cell position in the grid with (0,0) at bottom-left
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Position represents a cell position in the grid with (0,0) at bottom-left. Rows are never
+// flipped internally; Grid.Origin only changes how renderers draw them.
 type Position struct {
 	Row    int
 	Column int
 }
 
+// PositionOption configures NewPosition
+type PositionOption func(*positionOptions)
+
+// positionOptions holds the settings applied by PositionOption values
+type positionOptions struct {
+	allowNegative bool
+}
+
+// AllowNegative lets NewPosition accept negative coordinates, for positions relative to an
+// origin other than the grid's bottom-left cell
+func AllowNegative() PositionOption {
+	return func(o *positionOptions) {
+		o.allowNegative = true
+	}
+}
+
+// NewPosition creates a position, rejecting a negative row or column with a
+// NegativePositionError unless AllowNegative is given. Upper bounds depend on the grid and
+// are still checked by NewGrid. The struct literal remains available where coordinates are
+// known to be valid.
+func NewPosition(row, col int, opts ...PositionOption) (Position, error) {
+	var options positionOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	pos := Position{Row: row, Column: col}
+	if !options.allowNegative && (row < 0 || col < 0) {
+		return Position{}, &NegativePositionError{Position: pos}
+	}
+	return pos, nil
+}
+
 // ManhattanDistance calculates the Manhattan distance between two positions
 func (p Position) ManhattanDistance(other Position) int {
 	rowDiff := p.Row - other.Row
@@ -19,6 +60,68 @@ func (p Position) ManhattanDistance(other Position) int {
 	return rowDiff + colDiff
 }
 
+// MinRadiusToCover returns the smallest threshold whose diamond around center contains
+// every target, i.e. the largest Manhattan distance from center to a target. It returns 0
+// when there are no targets.
+func MinRadiusToCover(center Position, targets []Position) int {
+	radius := 0
+	for _, target := range targets {
+		radius = max(radius, center.ManhattanDistance(target))
+	}
+	return radius
+}
+
+// Add returns the position offset by delta
+func (p Position) Add(delta Position) Position {
+	return Position{Row: p.Row + delta.Row, Column: p.Column + delta.Column}
+}
+
+// Sub returns the offset from other to p, so that other.Add(p.Sub(other)) == p
+func (p Position) Sub(other Position) Position {
+	return Position{Row: p.Row - other.Row, Column: p.Column - other.Column}
+}
+
+// Translate returns the position moved by dr rows and dc columns
+func (p Position) Translate(dr, dc int) Position {
+	return Position{Row: p.Row + dr, Column: p.Column + dc}
+}
+
+// Neighbors4 returns the four orthogonally adjacent positions: north (row+1), south,
+// east (column+1), and west. Positions outside any grid are included; filter them with
+// Grid.IsValidPosition.
+func (p Position) Neighbors4() []Position {
+	return []Position{p.Translate(1, 0), p.Translate(-1, 0), p.Translate(0, 1), p.Translate(0, -1)}
+}
+
+// Neighbors8 returns Neighbors4 followed by the four diagonal neighbors: north-east,
+// north-west, south-east, and south-west. Like Neighbors4 it does not filter by bounds.
+func (p Position) Neighbors8() []Position {
+	return append(p.Neighbors4(), p.Translate(1, 1), p.Translate(1, -1), p.Translate(-1, 1), p.Translate(-1, -1))
+}
+
+// MarshalText encodes the position as "row,column"
+func (p Position) MarshalText() ([]byte, error) {
+	return []byte(strconv.Itoa(p.Row) + "," + strconv.Itoa(p.Column)), nil
+}
+
+// UnmarshalText decodes a position from "row,column"
+func (p *Position) UnmarshalText(text []byte) error {
+	rowText, colText, found := strings.Cut(string(text), ",")
+	if !found {
+		return &PositionFormatError{Text: string(text), Reason: "expected \"row,column\""}
+	}
+	row, err := strconv.Atoi(strings.TrimSpace(rowText))
+	if err != nil {
+		return &PositionFormatError{Text: string(text), Reason: fmt.Sprintf("invalid row %q", rowText)}
+	}
+	col, err := strconv.Atoi(strings.TrimSpace(colText))
+	if err != nil {
+		return &PositionFormatError{Text: string(text), Reason: fmt.Sprintf("invalid column %q", colText)}
+	}
+	p.Row, p.Column = row, col
+	return nil
+}
+
 // Abs returns the absolute value of an integer
 func Abs(x int) int {
 	if x < 0 {
@@ -26,3 +129,23 @@ func Abs(x int) int {
 	}
 	return x
 }
+
+// comparePositions orders positions by row, then column
+func comparePositions(a, b Position) int {
+	if c := cmp.Compare(a.Row, b.Row); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Column, b.Column)
+}
+
+// sortedPositions returns the members of a position set sorted by row, then column
+func sortedPositions(set map[Position]bool) []Position {
+	positions := make([]Position, 0, len(set))
+	for pos, ok := range set {
+		if ok {
+			positions = append(positions, pos)
+		}
+	}
+	slices.SortFunc(positions, comparePositions)
+	return positions
+}
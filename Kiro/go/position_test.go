@@ -0,0 +1,155 @@
+package gridneighborhoods_test
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestPositionTextRoundTrip(t *testing.T) {
+	pos := Position{Row: 5, Column: 5}
+	text, err := pos.MarshalText()
+	if err != nil || string(text) != "5,5" {
+		t.Fatalf("Expected \"5,5\", got %q (err=%v)", text, err)
+	}
+
+	var parsed Position
+	if err := parsed.UnmarshalText([]byte("10, -3")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if parsed != (Position{Row: 10, Column: -3}) {
+		t.Errorf("Expected {10 -3}, got %v", parsed)
+	}
+}
+
+func TestPositionUnmarshalTextRejectsMalformed(t *testing.T) {
+	for _, input := range []string{"", "5", "5;5", "a,5", "5,b", "5,5,5"} {
+		var pos Position
+		err := pos.UnmarshalText([]byte(input))
+		var formatErr *PositionFormatError
+		if !errors.As(err, &formatErr) {
+			t.Errorf("Input %q: expected PositionFormatError, got %v", input, err)
+		}
+	}
+}
+
+func TestPositionMapKeysEncodeAsJSON(t *testing.T) {
+	counts := map[Position]int{{Row: 3, Column: 3}: 1, {Row: 4, Column: 5}: 2}
+	data, err := json.Marshal(counts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if string(data) != `{"3,3":1,"4,5":2}` {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+
+	var decoded map[Position]int
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if decoded[Position{Row: 4, Column: 5}] != 2 || len(decoded) != 2 {
+		t.Errorf("Unexpected decoded map %v", decoded)
+	}
+}
+
+func TestPositionArithmetic(t *testing.T) {
+	p := Position{Row: 5, Column: 3}
+	if got := p.Add(Position{Row: -2, Column: 4}); got != (Position{Row: 3, Column: 7}) {
+		t.Errorf("Add: expected {3 7}, got %v", got)
+	}
+	if got := p.Sub(Position{Row: 7, Column: 1}); got != (Position{Row: -2, Column: 2}) {
+		t.Errorf("Sub: expected {-2 2}, got %v", got)
+	}
+	if got := p.Translate(-6, 0); got != (Position{Row: -1, Column: 3}) {
+		t.Errorf("Translate: expected {-1 3}, got %v", got)
+	}
+
+	other := Position{Row: -4, Column: 9}
+	if other.Add(p.Sub(other)) != p {
+		t.Error("Expected other.Add(p.Sub(other)) == p")
+	}
+	if delta := p.Sub(other); Abs(delta.Row)+Abs(delta.Column) != p.ManhattanDistance(other) {
+		t.Error("Expected |p.Sub(other)| to equal the Manhattan distance")
+	}
+}
+
+func TestMinRadiusToCover(t *testing.T) {
+	center := Position{Row: 5, Column: 5}
+	targets := []Position{{Row: 3, Column: 3}, {Row: 5, Column: 9}, {Row: 6, Column: 4}}
+	radius := MinRadiusToCover(center, targets)
+	if radius != 4 {
+		t.Errorf("Expected 4, got %d", radius)
+	}
+
+	// The radius is tight: every target is in the neighborhood, and one less drops one
+	grid, _ := NewGrid(11, 11, []Position{center})
+	calculator := NewNeighborhoodCalculator()
+	for _, n := range []int{radius, radius - 1} {
+		neighborhood := calculator.EnumerateNeighborhood(grid, center, n)
+		all := true
+		for _, target := range targets {
+			all = all && neighborhood[target]
+		}
+		if all != (n == radius) {
+			t.Errorf("N=%d: expected all targets covered to be %v", n, n == radius)
+		}
+	}
+
+	if radius := MinRadiusToCover(center, nil); radius != 0 {
+		t.Errorf("Expected 0 without targets, got %d", radius)
+	}
+}
+
+func TestPositionNeighbors(t *testing.T) {
+	p := Position{Row: 0, Column: 0}
+	expected4 := []Position{{Row: 1, Column: 0}, {Row: -1, Column: 0}, {Row: 0, Column: 1}, {Row: 0, Column: -1}}
+	if got := p.Neighbors4(); !slices.Equal(got, expected4) {
+		t.Errorf("Neighbors4: expected %v, got %v", expected4, got)
+	}
+
+	neighbors8 := p.Neighbors8()
+	if len(neighbors8) != 8 || !slices.Equal(neighbors8[:4], expected4) {
+		t.Fatalf("Neighbors8: expected Neighbors4 first, got %v", neighbors8)
+	}
+	seen := make(map[Position]bool)
+	for _, neighbor := range neighbors8 {
+		if seen[neighbor] || max(Abs(neighbor.Row), Abs(neighbor.Column)) != 1 {
+			t.Errorf("Neighbors8: unexpected or repeated neighbor %v", neighbor)
+		}
+		seen[neighbor] = true
+	}
+
+	// Callers filter by bounds themselves; a corner keeps two of its four neighbors
+	grid, _ := NewGrid(3, 3, nil)
+	inBounds := 0
+	for _, neighbor := range p.Neighbors4() {
+		if grid.IsValidPosition(neighbor) {
+			inBounds++
+		}
+	}
+	if inBounds != 2 {
+		t.Errorf("Expected 2 in-bounds neighbors of the corner, got %d", inBounds)
+	}
+}
+
+func TestNewPosition(t *testing.T) {
+	pos, err := NewPosition(3, 4)
+	if err != nil || pos != (Position{Row: 3, Column: 4}) {
+		t.Errorf("Expected (3,4), got %v (err=%v)", pos, err)
+	}
+
+	var negativeErr *NegativePositionError
+	for _, coords := range [][2]int{{-1, 0}, {0, -1}, {-2, -3}} {
+		if _, err := NewPosition(coords[0], coords[1]); !errors.As(err, &negativeErr) {
+			t.Errorf("NewPosition(%d, %d): expected NegativePositionError, got %v", coords[0], coords[1], err)
+		}
+	}
+
+	pos, err = NewPosition(-2, 5, AllowNegative())
+	if err != nil || pos != (Position{Row: -2, Column: 5}) {
+		t.Errorf("Expected (-2,5) with AllowNegative, got %v (err=%v)", pos, err)
+	}
+}
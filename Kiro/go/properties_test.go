@@ -287,6 +287,9 @@ func TestProperty11MaximumDistanceThreshold(t *testing.T) {
 
 		// Calculate maximum possible Manhattan distance
 		maxPossibleDistance := (height - 1) + (width - 1)
+		if grid.MaxManhattanDistance() != maxPossibleDistance {
+			t.Fatalf("Expected MaxManhattanDistance %d, got %d", maxPossibleDistance, grid.MaxManhattanDistance())
+		}
 
 		// Use a distance threshold that exceeds the maximum
 		excessiveThreshold := maxPossibleDistance + 10
@@ -294,7 +297,10 @@ func TestProperty11MaximumDistanceThreshold(t *testing.T) {
 		count, _ := calculator.CountNeighborhoodCells(grid, excessiveThreshold)
 
 		// All grid cells should be counted
-		expectedCount := height * width
+		expectedCount := grid.CellCount()
+		if expectedCount != height*width {
+			t.Fatalf("Expected CellCount %d, got %d", height*width, expectedCount)
+		}
 		if count != expectedCount {
 			t.Fatalf("Expected count %d (all cells), got %d", expectedCount, count)
 		}
@@ -374,3 +380,106 @@ func TestProperty12DegenerateGridHandling(t *testing.T) {
 		}
 	})
 }
+
+// Property 13: Clamped Enumeration Matches Brute Force
+func TestProperty13ClampedEnumerationMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 30).Draw(t, "height")
+		width := rapid.IntRange(1, 30).Draw(t, "width")
+		centerRow := rapid.IntRange(0, height-1).Draw(t, "centerRow")
+		centerCol := rapid.IntRange(0, width-1).Draw(t, "centerCol")
+		distanceThreshold := rapid.IntRange(0, 40).Draw(t, "distanceThreshold")
+
+		center := Position{Row: centerRow, Column: centerCol}
+		grid, _ := NewGrid(height, width, []Position{center})
+		calculator := NewNeighborhoodCalculator()
+		neighborhood := calculator.EnumerateNeighborhood(grid, center, distanceThreshold)
+
+		// Reference: test every candidate in the unclipped diamond against the bounds
+		expected := make(map[Position]bool)
+		for deltaRow := -distanceThreshold; deltaRow <= distanceThreshold; deltaRow++ {
+			remainingDistance := distanceThreshold - Abs(deltaRow)
+			for deltaCol := -remainingDistance; deltaCol <= remainingDistance; deltaCol++ {
+				candidate := Position{Row: centerRow + deltaRow, Column: centerCol + deltaCol}
+				if grid.IsValidPosition(candidate) {
+					expected[candidate] = true
+				}
+			}
+		}
+
+		if len(neighborhood) != len(expected) {
+			t.Fatalf("Expected %d cells, got %d", len(expected), len(neighborhood))
+		}
+		for pos := range expected {
+			if !neighborhood[pos] {
+				t.Fatalf("Missing cell %v", pos)
+			}
+		}
+	})
+}
+
+// Property 14: Filtered Unclipped Enumeration Matches Clipped Enumeration
+func TestProperty14FilteredMatchesClipped(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 20).Draw(t, "height")
+		width := rapid.IntRange(1, 20).Draw(t, "width")
+		numPositions := rapid.IntRange(0, 5).Draw(t, "numPositions")
+		positions := make([]Position, 0, numPositions)
+		for i := 0; i < numPositions; i++ {
+			row := rapid.IntRange(0, height-1).Draw(t, "pos_row")
+			col := rapid.IntRange(0, width-1).Draw(t, "pos_col")
+			positions = append(positions, Position{Row: row, Column: col})
+		}
+		distanceThreshold := rapid.IntRange(0, 15).Draw(t, "distanceThreshold")
+
+		grid, _ := NewGrid(height, width, positions)
+		calculator := NewNeighborhoodCalculator()
+		filtered := calculator.GetNeighborhoodCellsFiltered(grid, distanceThreshold)
+		clipped := calculator.GetNeighborhoodCells(grid, distanceThreshold)
+
+		if len(filtered) != len(clipped) {
+			t.Fatalf("Expected %d cells, got %d", len(clipped), len(filtered))
+		}
+		for pos := range clipped {
+			if !filtered[pos] {
+				t.Fatalf("Filtered result missing %v", pos)
+			}
+		}
+	})
+}
+
+// Property 15: Total With Duplicates Bounds The Union
+func TestProperty15TotalWithDuplicatesBoundsUnion(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		height := rapid.IntRange(1, 30).Draw(t, "height")
+		width := rapid.IntRange(1, 30).Draw(t, "width")
+		numPositions := rapid.IntRange(0, 10).Draw(t, "numPositions")
+		positions := make([]Position, 0, numPositions)
+		for i := 0; i < numPositions; i++ {
+			row := rapid.IntRange(0, height-1).Draw(t, "pos_row")
+			col := rapid.IntRange(0, width-1).Draw(t, "pos_col")
+			positions = append(positions, Position{Row: row, Column: col})
+		}
+		distanceThreshold := rapid.IntRange(0, 20).Draw(t, "distanceThreshold")
+
+		grid, _ := NewGrid(height, width, positions)
+		calculator := NewNeighborhoodCalculator()
+		total, err := calculator.TotalWithDuplicates(grid, distanceThreshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		sumOfIndividual := 0
+		for _, pos := range positions {
+			sumOfIndividual += len(calculator.EnumerateNeighborhood(grid, pos, distanceThreshold))
+		}
+		if total != sumOfIndividual {
+			t.Fatalf("Expected %d, got %d", sumOfIndividual, total)
+		}
+
+		count, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if count > total {
+			t.Fatalf("Union count %d should be <= total with duplicates %d", count, total)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package gridneighborhoods
+
+// quadtreeLeafSize is the most cells a quadtree leaf stores before it is split
+const quadtreeLeafSize = 16
+
+// Quadtree indexes a set of covered cells for fast rectangular range counts. Each node
+// records how many cells fall in its quadrant, so ranges that contain whole quadrants
+// are answered without visiting their cells.
+type Quadtree struct {
+	root *quadtreeNode
+}
+
+// quadtreeNode covers the inclusive rectangle [minRow,maxRow] x [minCol,maxCol]. A node is
+// a leaf, holding its cells, when it has no children.
+type quadtreeNode struct {
+	minRow, minCol, maxRow, maxCol int
+	count                          int
+	children                       []*quadtreeNode
+	cells                          []Position
+}
+
+// CoverageQuadtree builds a Quadtree over the neighborhood union. It returns nil for a nil
+// grid or a negative threshold; RangeCount on a nil Quadtree is always 0.
+func (nc *NeighborhoodCalculator) CoverageQuadtree(grid *Grid, distanceThreshold int) *Quadtree {
+	if grid == nil || distanceThreshold < 0 {
+		return nil
+	}
+	cells := sortedPositions(nc.GetNeighborhoodCells(grid, distanceThreshold))
+	return &Quadtree{root: buildQuadtreeNode(cells, 0, 0, grid.Height-1, grid.Width-1)}
+}
+
+// buildQuadtreeNode builds the node for a rectangle from the cells inside it
+func buildQuadtreeNode(cells []Position, minRow, minCol, maxRow, maxCol int) *quadtreeNode {
+	node := &quadtreeNode{minRow: minRow, minCol: minCol, maxRow: maxRow, maxCol: maxCol, count: len(cells)}
+	area := (maxRow - minRow + 1) * (maxCol - minCol + 1)
+	// Small and fully covered quadrants need no further splitting
+	if len(cells) <= quadtreeLeafSize || len(cells) == area {
+		if len(cells) < area {
+			node.cells = cells
+		}
+		return node
+	}
+
+	midRow := minRow + (maxRow-minRow)/2
+	midCol := minCol + (maxCol-minCol)/2
+	quadrants := [4][4]int{
+		{minRow, minCol, midRow, midCol},
+		{minRow, midCol + 1, midRow, maxCol},
+		{midRow + 1, minCol, maxRow, midCol},
+		{midRow + 1, midCol + 1, maxRow, maxCol},
+	}
+	var parts [4][]Position
+	for _, pos := range cells {
+		index := 0
+		if pos.Row > midRow {
+			index += 2
+		}
+		if pos.Column > midCol {
+			index++
+		}
+		parts[index] = append(parts[index], pos)
+	}
+	for i, q := range quadrants {
+		// A single row or column leaves two quadrants empty in one dimension
+		if q[0] > q[2] || q[1] > q[3] || len(parts[i]) == 0 {
+			continue
+		}
+		node.children = append(node.children, buildQuadtreeNode(parts[i], q[0], q[1], q[2], q[3]))
+	}
+	return node
+}
+
+// RangeCount returns the number of covered cells in the inclusive rectangle
+// [minRow,maxRow] x [minCol,maxCol]. Parts of the rectangle outside the grid hold no
+// cells, and an empty rectangle counts 0.
+func (q *Quadtree) RangeCount(minRow, minCol, maxRow, maxCol int) int {
+	if q == nil || minRow > maxRow || minCol > maxCol {
+		return 0
+	}
+	return q.root.rangeCount(minRow, minCol, maxRow, maxCol)
+}
+
+// rangeCount counts the node's cells inside the query rectangle
+func (n *quadtreeNode) rangeCount(minRow, minCol, maxRow, maxCol int) int {
+	if n.count == 0 || maxRow < n.minRow || minRow > n.maxRow || maxCol < n.minCol || minCol > n.maxCol {
+		return 0
+	}
+	if minRow <= n.minRow && maxRow >= n.maxRow && minCol <= n.minCol && maxCol >= n.maxCol {
+		return n.count
+	}
+	if len(n.children) > 0 {
+		total := 0
+		for _, child := range n.children {
+			total += child.rangeCount(minRow, minCol, maxRow, maxCol)
+		}
+		return total
+	}
+	if n.cells == nil {
+		// A fully covered leaf: count the overlap area directly
+		return (min(maxRow, n.maxRow) - max(minRow, n.minRow) + 1) * (min(maxCol, n.maxCol) - max(minCol, n.minCol) + 1)
+	}
+	total := 0
+	for _, pos := range n.cells {
+		if pos.Row >= minRow && pos.Row <= maxRow && pos.Column >= minCol && pos.Column <= maxCol {
+			total++
+		}
+	}
+	return total
+}
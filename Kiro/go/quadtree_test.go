@@ -0,0 +1,62 @@
+package gridneighborhoods_test
+
+import (
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestCoverageQuadtreeScenario4(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	tree := NewNeighborhoodCalculator().CoverageQuadtree(grid, 2)
+
+	if count := tree.RangeCount(0, 0, 10, 10); count != 22 {
+		t.Errorf("Expected 22 over the whole grid, got %d", count)
+	}
+	// Row 3 spans columns 1 through 6
+	if count := tree.RangeCount(3, 0, 3, 10); count != 6 {
+		t.Errorf("Expected 6 on row 3, got %d", count)
+	}
+	if count := tree.RangeCount(-5, -5, 20, 20); count != 22 {
+		t.Errorf("Expected ranges past the grid to be clipped, got %d", count)
+	}
+	if count := tree.RangeCount(8, 0, 10, 10); count != 0 {
+		t.Errorf("Expected nothing above row 7, got %d", count)
+	}
+	if count := tree.RangeCount(5, 5, 4, 4); count != 0 {
+		t.Errorf("Expected 0 for an empty rectangle, got %d", count)
+	}
+
+	var missing *Quadtree
+	if missing.RangeCount(0, 0, 10, 10) != 0 || NewNeighborhoodCalculator().CoverageQuadtree(nil, 2) != nil {
+		t.Error("Expected a nil grid to give a nil Quadtree that counts 0")
+	}
+}
+
+func TestCoverageQuadtreeMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 40, 6)
+		distanceThreshold := rapid.IntRange(0, 15).Draw(t, "distanceThreshold")
+		calculator := NewNeighborhoodCalculator()
+		tree := calculator.CoverageQuadtree(grid, distanceThreshold)
+		cells := calculator.GetNeighborhoodCells(grid, distanceThreshold)
+
+		for i := 0; i < 10; i++ {
+			minRow := rapid.IntRange(-2, grid.Height).Draw(t, "minRow")
+			minCol := rapid.IntRange(-2, grid.Width).Draw(t, "minCol")
+			maxRow := rapid.IntRange(minRow, grid.Height+2).Draw(t, "maxRow")
+			maxCol := rapid.IntRange(minCol, grid.Width+2).Draw(t, "maxCol")
+			expected := 0
+			for pos := range cells {
+				if pos.Row >= minRow && pos.Row <= maxRow && pos.Column >= minCol && pos.Column <= maxCol {
+					expected++
+				}
+			}
+			if count := tree.RangeCount(minRow, minCol, maxRow, maxCol); count != expected {
+				t.Fatalf("Range [%d,%d]x[%d,%d]: expected %d, got %d", minRow, maxRow, minCol, maxCol, expected, count)
+			}
+		}
+	})
+}
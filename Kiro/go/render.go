@@ -0,0 +1,210 @@
+package gridneighborhoods
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// CoordinateSystem selects which corner holds row 0 when a grid is drawn as a raster
+type CoordinateSystem int
+
+const (
+	// BottomLeft draws row 0 as the bottom line, matching Position and the GeoJSON output
+	BottomLeft CoordinateSystem = iota
+	// TopLeft draws row 0 as the top line, as in matrices and image files
+	TopLeft
+)
+
+// cellState classifies a cell for the raster renderers
+type cellState int
+
+const (
+	uncoveredCell cellState = iota
+	coveredCell
+	sourceCell
+	blockedCell
+)
+
+// asciiGlyphs and pngPalette are indexed by cellState
+var (
+	asciiGlyphs = [...]byte{'.', '#', '*', 'x'}
+	pngPalette  = color.Palette{
+		color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		color.RGBA{R: 0x9e, G: 0xca, B: 0xe1, A: 0xff},
+		color.RGBA{R: 0x08, G: 0x45, B: 0x94, A: 0xff},
+		color.RGBA{A: 0xff},
+	}
+)
+
+// geoJSONFeatureCollection is the top-level GeoJSON document
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature is a single covered cell
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONGeometry   `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+// geoJSONGeometry holds either Point or Polygon coordinates
+type geoJSONGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// geoJSONProperties identifies the grid cell a feature represents
+type geoJSONProperties struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// RenderGeoJSON writes every covered cell as a GeoJSON Point feature at the cell center.
+// Columns map to x and rows to y, so (0,0) is the bottom-left cell as in Position.
+func (nc *NeighborhoodCalculator) RenderGeoJSON(w io.Writer, grid *Grid, distanceThreshold int) error {
+	return nc.renderGeoJSON(w, grid, distanceThreshold, false)
+}
+
+// RenderGeoJSONPolygons writes every covered cell as a unit-square GeoJSON Polygon feature
+func (nc *NeighborhoodCalculator) RenderGeoJSONPolygons(w io.Writer, grid *Grid, distanceThreshold int) error {
+	return nc.renderGeoJSON(w, grid, distanceThreshold, true)
+}
+
+// renderGeoJSON writes covered cells in row-major order as Point or Polygon features
+func (nc *NeighborhoodCalculator) renderGeoJSON(w io.Writer, grid *Grid, distanceThreshold int, asPolygons bool) error {
+	if grid == nil {
+		return ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	collection := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	nc.ForEachCell(grid, distanceThreshold, func(pos Position, covered bool) {
+		if !covered {
+			return
+		}
+		x, y := float64(pos.Column), float64(pos.Row)
+		geometry := geoJSONGeometry{Type: "Point", Coordinates: []float64{x + 0.5, y + 0.5}}
+		if asPolygons {
+			ring := [][]float64{{x, y}, {x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x, y}}
+			geometry = geoJSONGeometry{Type: "Polygon", Coordinates: [][][]float64{ring}}
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geometry,
+			Properties: geoJSONProperties{Row: pos.Row, Column: pos.Column},
+		})
+	})
+
+	return json.NewEncoder(w).Encode(collection)
+}
+
+// RenderASCII writes the grid one line per row using '*' for positive cells, '#' for other
+// covered cells, '.' for uncovered cells and 'x' for blocked cells. grid.Origin decides
+// whether row 0 is the first or the last line.
+func (nc *NeighborhoodCalculator) RenderASCII(w io.Writer, grid *Grid, distanceThreshold int) error {
+	states, err := nc.rasterize(grid, distanceThreshold)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	for _, line := range states {
+		for _, state := range line {
+			bw.WriteByte(asciiGlyphs[state])
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// RenderPNG writes the grid as a PNG image with one pixel per cell, using the same cell
+// classes as RenderASCII. grid.Origin decides whether row 0 is the top or bottom pixel row.
+func (nc *NeighborhoodCalculator) RenderPNG(w io.Writer, grid *Grid, distanceThreshold int) error {
+	states, err := nc.rasterize(grid, distanceThreshold)
+	if err != nil {
+		return err
+	}
+	img := image.NewPaletted(image.Rect(0, 0, grid.Width, grid.Height), pngPalette)
+	for y, line := range states {
+		for x, state := range line {
+			img.SetColorIndex(x, y, uint8(state))
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// RenderGnuplot writes one "row col value" line per cell, where value is 2 for positive
+// cells, 1 for other covered cells and 0 otherwise, with a blank line after each row so
+// gnuplot's pm3d and image plot styles read it as a matrix
+func (nc *NeighborhoodCalculator) RenderGnuplot(w io.Writer, grid *Grid, distanceThreshold int) error {
+	if grid == nil {
+		return ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if err := nc.checkGridCells(grid); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	nc.ForEachCell(grid, distanceThreshold, func(pos Position, covered bool) {
+		value := 0
+		switch {
+		case grid.IsPositive(pos):
+			value = 2
+		case covered:
+			value = 1
+		}
+		fmt.Fprintf(bw, "%d %d %d\n", pos.Row, pos.Column, value)
+		if pos.Column == grid.Width-1 {
+			bw.WriteByte('\n')
+		}
+	})
+	return bw.Flush()
+}
+
+// rasterize classifies every cell in display order: the result's [y][x] is the cell drawn
+// on line y, counted from the top, and column x
+func (nc *NeighborhoodCalculator) rasterize(grid *Grid, distanceThreshold int) ([][]cellState, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+	if err := nc.checkGridCells(grid); err != nil {
+		return nil, err
+	}
+
+	covered := nc.GetNeighborhoodCells(grid, distanceThreshold)
+
+	states := make([][]cellState, grid.Height)
+	for y := range states {
+		row := y
+		if grid.Origin == BottomLeft {
+			row = grid.Height - 1 - y
+		}
+		states[y] = make([]cellState, grid.Width)
+		for col := range states[y] {
+			pos := Position{Row: row, Column: col}
+			switch {
+			case grid.IsBlocked(pos):
+				states[y][col] = blockedCell
+			case grid.IsPositive(pos):
+				states[y][col] = sourceCell
+			case covered[pos]:
+				states[y][col] = coveredCell
+			}
+		}
+	}
+	return states, nil
+}
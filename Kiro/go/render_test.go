@@ -0,0 +1,159 @@
+package gridneighborhoods_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image/color"
+	"image/png"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+// decodedFeatureCollection mirrors the GeoJSON output for assertions
+type decodedFeatureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+		Properties struct {
+			Row    int `json:"row"`
+			Column int `json:"column"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+func TestRenderGeoJSONPoints(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 1}})
+	calculator := NewNeighborhoodCalculator()
+
+	var buf bytes.Buffer
+	if err := calculator.RenderGeoJSON(&buf, grid, 3); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded decodedFeatureCollection
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if decoded.Type != "FeatureCollection" {
+		t.Errorf("Expected FeatureCollection, got %q", decoded.Type)
+	}
+	// Scenario 2 geometry
+	if len(decoded.Features) != 21 {
+		t.Fatalf("Expected 21 features, got %d", len(decoded.Features))
+	}
+
+	cells := calculator.GetNeighborhoodCells(grid, 3)
+	for _, feature := range decoded.Features {
+		if feature.Geometry.Type != "Point" {
+			t.Fatalf("Expected Point geometry, got %q", feature.Geometry.Type)
+		}
+		pos := Position{Row: feature.Properties.Row, Column: feature.Properties.Column}
+		if !cells[pos] {
+			t.Errorf("Feature %v is not a covered cell", pos)
+		}
+	}
+}
+
+func TestRenderGeoJSONPolygons(t *testing.T) {
+	grid, _ := NewGrid(1, 1, []Position{{Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+
+	var buf bytes.Buffer
+	if err := calculator.RenderGeoJSONPolygons(&buf, grid, 0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded decodedFeatureCollection
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(decoded.Features) != 1 || decoded.Features[0].Geometry.Type != "Polygon" {
+		t.Fatalf("Expected a single Polygon feature, got %+v", decoded.Features)
+	}
+	var rings [][][]float64
+	if err := json.Unmarshal(decoded.Features[0].Geometry.Coordinates, &rings); err != nil {
+		t.Fatalf("Unexpected polygon coordinates: %v", err)
+	}
+	if len(rings) != 1 || len(rings[0]) != 5 || rings[0][0][0] != rings[0][4][0] || rings[0][0][1] != rings[0][4][1] {
+		t.Errorf("Expected a closed 5-point ring, got %v", rings)
+	}
+}
+
+func TestRenderASCIIRespectsOrigin(t *testing.T) {
+	grid, _ := NewGrid(3, 4, []Position{{Row: 0, Column: 0}})
+	grid.AddBlockedRect(2, 3, 2, 3)
+	calculator := NewNeighborhoodCalculator()
+
+	var buf bytes.Buffer
+	if err := calculator.RenderASCII(&buf, grid, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if expected := "...x\n#...\n*#..\n"; buf.String() != expected {
+		t.Errorf("BottomLeft: expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	grid.Origin = TopLeft
+	buf.Reset()
+	calculator.RenderASCII(&buf, grid, 1)
+	if expected := "*#..\n#...\n...x\n"; buf.String() != expected {
+		t.Errorf("TopLeft: expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	if err := calculator.RenderASCII(&buf, nil, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestRenderPNGRespectsOrigin(t *testing.T) {
+	grid, _ := NewGrid(3, 4, []Position{{Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+
+	sourcePixel := func() (color.Color, color.Color) {
+		var buf bytes.Buffer
+		if err := calculator.RenderPNG(&buf, grid, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil {
+			t.Fatalf("Expected a valid PNG, got %v", err)
+		}
+		if bounds := img.Bounds(); bounds.Dx() != 4 || bounds.Dy() != 3 {
+			t.Fatalf("Expected 4x3 image, got %v", bounds)
+		}
+		return img.At(0, 0), img.At(0, 2)
+	}
+
+	top, bottom := sourcePixel()
+	if top == bottom {
+		t.Fatal("Expected the source pixel to differ from the uncovered corner")
+	}
+	grid.Origin = TopLeft
+	flippedTop, flippedBottom := sourcePixel()
+	if flippedTop != bottom || flippedBottom != top {
+		t.Error("Expected TopLeft to mirror the BottomLeft image vertically")
+	}
+}
+
+func TestRenderGnuplot(t *testing.T) {
+	grid, _ := NewGrid(2, 3, []Position{{Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+
+	var buf bytes.Buffer
+	if err := calculator.RenderGnuplot(&buf, grid, 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := "0 0 2\n0 1 1\n0 2 0\n\n1 0 1\n1 1 0\n1 2 0\n\n"
+	if buf.String() != expected {
+		t.Errorf("Expected\n%q\ngot\n%q", expected, buf.String())
+	}
+
+	var thresholdErr *InvalidDistanceThresholdError
+	if err := calculator.RenderGnuplot(&buf, grid, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package gridneighborhoods
+
+// Shape is a source made of one or more cells
+type Shape interface {
+	// Cells expands the shape into the positions it occupies
+	Cells() []Position
+}
+
+// CellShape is a single-cell source
+type CellShape struct {
+	Position Position
+}
+
+// Cells returns the single position of the shape
+func (s CellShape) Cells() []Position {
+	return []Position{s.Position}
+}
+
+// LineShape is a straight segment of sources between two endpoints, inclusive
+type LineShape struct {
+	From Position
+	To   Position
+}
+
+// Cells returns the cells on the segment, traced with Bresenham's line algorithm
+func (s LineShape) Cells() []Position {
+	return linePositions(s.From, s.To)
+}
+
+// RectShape is a filled rectangle of sources with inclusive corners
+type RectShape struct {
+	MinRow int
+	MinCol int
+	MaxRow int
+	MaxCol int
+}
+
+// Cells returns every cell in the rectangle; an inverted rectangle has no cells
+func (s RectShape) Cells() []Position {
+	var cells []Position
+	for row := s.MinRow; row <= s.MaxRow; row++ {
+		for col := s.MinCol; col <= s.MaxCol; col++ {
+			cells = append(cells, Position{Row: row, Column: col})
+		}
+	}
+	return cells
+}
+
+// linePositions traces the cells from one position to another with Bresenham's algorithm
+func linePositions(from, to Position) []Position {
+	deltaRow := Abs(to.Row - from.Row)
+	deltaCol := Abs(to.Column - from.Column)
+	stepRow, stepCol := 1, 1
+	if to.Row < from.Row {
+		stepRow = -1
+	}
+	if to.Column < from.Column {
+		stepCol = -1
+	}
+
+	cells := make([]Position, 0, max(deltaRow, deltaCol)+1)
+	current := from
+	errorTerm := deltaCol - deltaRow
+	for {
+		cells = append(cells, current)
+		if current == to {
+			return cells
+		}
+		doubled := 2 * errorTerm
+		if doubled > -deltaRow {
+			errorTerm -= deltaRow
+			current.Column += stepCol
+		}
+		if doubled < deltaCol {
+			errorTerm += deltaCol
+			current.Row += stepRow
+		}
+	}
+}
+
+// CountNeighborhoodCellsFromShapes counts the unique cells within distanceThreshold of any
+// cell of any shape, leaving out the grid's excluded cells. Every shape cell must lie inside
+// the grid.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsFromShapes(grid *Grid, shapes []Shape, distanceThreshold int) (int, error) {
+	if grid == nil {
+		return 0, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return 0, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	// Expand and validate every shape before enumerating
+	sources := make(map[Position]bool)
+	for _, shape := range shapes {
+		for _, pos := range shape.Cells() {
+			if !nc.boundaryHandler.IsWithinBounds(pos, grid) {
+				return 0, &PositionOutOfBoundsError{Position: pos, Height: grid.Height, Width: grid.Width}
+			}
+			sources[pos] = true
+		}
+	}
+
+	excluded := grid.excludedCells()
+	allCells := make(map[Position]bool)
+	for center := range sources {
+		for pos := range nc.enumerateNeighborhood(grid, center, distanceThreshold) {
+			if !excluded[pos] {
+				allCells[pos] = true
+			}
+		}
+	}
+	return len(allCells), nil
+}
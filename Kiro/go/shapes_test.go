@@ -0,0 +1,64 @@
+package gridneighborhoods_test
+
+import (
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestShapeCells(t *testing.T) {
+	if cells := (CellShape{Position: Position{Row: 2, Column: 3}}).Cells(); len(cells) != 1 || cells[0] != (Position{Row: 2, Column: 3}) {
+		t.Errorf("Unexpected CellShape cells %v", cells)
+	}
+
+	line := LineShape{From: Position{Row: 0, Column: 0}, To: Position{Row: 2, Column: 4}}.Cells()
+	expected := []Position{{Row: 0, Column: 0}, {Row: 0, Column: 1}, {Row: 1, Column: 2}, {Row: 1, Column: 3}, {Row: 2, Column: 4}}
+	if len(line) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, line)
+	}
+	for i := range expected {
+		if line[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, line)
+		}
+	}
+
+	if cells := (RectShape{MinRow: 1, MinCol: 1, MaxRow: 2, MaxCol: 3}).Cells(); len(cells) != 6 {
+		t.Errorf("Expected 6 rectangle cells, got %d", len(cells))
+	}
+	if cells := (RectShape{MinRow: 2, MinCol: 1, MaxRow: 1, MaxCol: 3}).Cells(); len(cells) != 0 {
+		t.Errorf("Expected no cells for inverted rectangle, got %v", cells)
+	}
+}
+
+func TestCountNeighborhoodCellsFromShapes(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{})
+	calculator := NewNeighborhoodCalculator()
+
+	// A single-cell shape matches Scenario 1
+	count, err := calculator.CountNeighborhoodCellsFromShapes(grid, []Shape{CellShape{Position: Position{Row: 5, Column: 5}}}, 3)
+	if err != nil || count != 25 {
+		t.Errorf("Expected 25, got %d (err=%v)", count, err)
+	}
+
+	// Shapes combine like Scenario 4's positive cells
+	count, _ = calculator.CountNeighborhoodCellsFromShapes(grid, []Shape{
+		CellShape{Position: Position{Row: 3, Column: 3}},
+		RectShape{MinRow: 4, MinCol: 5, MaxRow: 4, MaxCol: 5},
+	}, 2)
+	if count != 22 {
+		t.Errorf("Expected 22, got %d", count)
+	}
+
+	// A full-width horizontal wall with N=1 covers its row and the rows on either side
+	count, _ = calculator.CountNeighborhoodCellsFromShapes(grid, []Shape{
+		LineShape{From: Position{Row: 5, Column: 0}, To: Position{Row: 5, Column: 10}},
+	}, 1)
+	if count != 33 {
+		t.Errorf("Expected 33, got %d", count)
+	}
+
+	_, err = calculator.CountNeighborhoodCellsFromShapes(grid, []Shape{RectShape{MinRow: 9, MinCol: 9, MaxRow: 11, MaxCol: 11}}, 1)
+	if _, ok := err.(*PositionOutOfBoundsError); !ok {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+}
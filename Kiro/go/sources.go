@@ -0,0 +1,114 @@
+package gridneighborhoods
+
+import (
+	"cmp"
+	"slices"
+)
+
+// KNearestPositives returns up to k positive cells sorted by Manhattan distance from
+// from, ascending. Ties are broken by row, then column; repeated positive cells are
+// returned once each time they appear.
+func (nc *NeighborhoodCalculator) KNearestPositives(grid *Grid, from Position, k int) []Position {
+	if grid == nil || k <= 0 {
+		return nil
+	}
+
+	candidates := slices.Clone(grid.PositiveCells)
+	slices.SortFunc(candidates, func(a, b Position) int {
+		distanceA := nc.gridDistance(grid, from, a)
+		distanceB := nc.gridDistance(grid, from, b)
+		if c := cmp.Compare(distanceA, distanceB); c != 0 {
+			return c
+		}
+		return comparePositions(a, b)
+	})
+	return candidates[:min(k, len(candidates))]
+}
+
+// PositiveCentroid returns the mean row and column of the positive cells, counting
+// repeated cells each time they appear. ok is false when the grid has no positive cells.
+func (nc *NeighborhoodCalculator) PositiveCentroid(grid *Grid) (rowMean, colMean float64, ok bool) {
+	if grid == nil || !grid.HasPositiveCells() {
+		return 0, 0, false
+	}
+	rowSum, colSum := 0, 0
+	for _, pos := range grid.PositiveCells {
+		rowSum += pos.Row
+		colSum += pos.Column
+	}
+	count := float64(len(grid.PositiveCells))
+	return float64(rowSum) / count, float64(colSum) / count, true
+}
+
+// NeighborhoodSizes returns the clipped neighborhood size of each positive cell, in
+// PositiveCells order. Each size matches CountSingleNeighborhood for that source.
+func (nc *NeighborhoodCalculator) NeighborhoodSizes(grid *Grid, distanceThreshold int) ([]int, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if distanceThreshold < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: distanceThreshold}
+	}
+
+	sizes := make([]int, len(grid.PositiveCells))
+	for i, center := range grid.PositiveCells {
+		size, err := nc.CountSingleNeighborhood(grid, center, distanceThreshold)
+		if err != nil {
+			return nil, err
+		}
+		sizes[i] = size
+	}
+	return sizes, nil
+}
+
+// SourceSizeStats summarizes NeighborhoodSizes: the smallest and largest size, their mean,
+// and their median (the mean of the two middle sizes for an even number of sources). ok
+// is false when the grid is nil, has no positive cells, or the threshold is negative.
+func (nc *NeighborhoodCalculator) SourceSizeStats(grid *Grid, distanceThreshold int) (minSize, maxSize int, mean, median float64, ok bool) {
+	sizes, err := nc.NeighborhoodSizes(grid, distanceThreshold)
+	if err != nil || len(sizes) == 0 {
+		return 0, 0, 0, 0, false
+	}
+
+	slices.Sort(sizes)
+	sum := 0
+	for _, size := range sizes {
+		sum += size
+	}
+	middle := len(sizes) / 2
+	median = float64(sizes[middle])
+	if len(sizes)%2 == 0 {
+		median = float64(sizes[middle-1]+sizes[middle]) / 2
+	}
+	return sizes[0], sizes[len(sizes)-1], float64(sum) / float64(len(sizes)), median, true
+}
+
+// PositiveHull returns the in-bounds cells of the smallest diamond-aligned region holding
+// every positive cell: the cells whose row+column and row-column both lie within the range
+// those sums and differences take over the sources. This 45-degree rotated rectangle is
+// the L1 analogue of a bounding box. It depends on neither a threshold nor the blocked
+// mask, and is empty for a nil grid or a grid without positive cells.
+func (nc *NeighborhoodCalculator) PositiveHull(grid *Grid) map[Position]bool {
+	hull := make(map[Position]bool)
+	if grid == nil || !grid.HasPositiveCells() {
+		return hull
+	}
+
+	first := grid.PositiveCells[0]
+	minSum, maxSum := first.Row+first.Column, first.Row+first.Column
+	minDiff, maxDiff := first.Row-first.Column, first.Row-first.Column
+	for _, pos := range grid.PositiveCells[1:] {
+		minSum, maxSum = min(minSum, pos.Row+pos.Column), max(maxSum, pos.Row+pos.Column)
+		minDiff, maxDiff = min(minDiff, pos.Row-pos.Column), max(maxDiff, pos.Row-pos.Column)
+	}
+
+	for row := 0; row < grid.Height; row++ {
+		// column = sum - row and column = row - diff bound the row's span
+		minCol := max(0, max(minSum-row, row-maxDiff))
+		maxCol := min(grid.Width-1, min(maxSum-row, row-minDiff))
+		for col := minCol; col <= maxCol; col++ {
+			hull[Position{Row: row, Column: col}] = true
+		}
+	}
+	return hull
+}
@@ -0,0 +1,157 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestKNearestPositives(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 10, Column: 10}, {Row: 5, Column: 7}, {Row: 3, Column: 5}, {Row: 5, Column: 3}, {Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+
+	// (3,5), (5,3), and (5,7) are 2 away from (5,5), and both corners are 10 away;
+	// ties sort by row, then column
+	nearest := calculator.KNearestPositives(grid, Position{Row: 5, Column: 5}, 4)
+	expected := []Position{{Row: 3, Column: 5}, {Row: 5, Column: 3}, {Row: 5, Column: 7}, {Row: 0, Column: 0}}
+	if len(nearest) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, nearest)
+	}
+	for i := range expected {
+		if nearest[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, nearest)
+		}
+	}
+
+	if all := calculator.KNearestPositives(grid, Position{Row: 0, Column: 0}, 10); len(all) != 5 || all[0] != (Position{Row: 0, Column: 0}) {
+		t.Errorf("Expected all 5 positives starting with (0,0), got %v", all)
+	}
+	if none := calculator.KNearestPositives(grid, Position{}, 0); len(none) != 0 {
+		t.Errorf("Expected no positives for k=0, got %v", none)
+	}
+	if grid.PositiveCells[0] != (Position{Row: 10, Column: 10}) {
+		t.Error("KNearestPositives should not reorder the grid's positive cells")
+	}
+}
+
+func TestPositiveCentroid(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Scenario 4 sources (3,3) and (4,5)
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	rowMean, colMean, ok := calculator.PositiveCentroid(grid)
+	if !ok || rowMean != 3.5 || colMean != 4 {
+		t.Errorf("Expected (3.5, 4), got (%v, %v) ok=%v", rowMean, colMean, ok)
+	}
+
+	// Repeated sources weigh in each time
+	grid, _ = NewGrid(11, 11, []Position{{Row: 0, Column: 0}, {Row: 0, Column: 0}, {Row: 9, Column: 3}})
+	if rowMean, colMean, _ := calculator.PositiveCentroid(grid); rowMean != 3 || colMean != 1 {
+		t.Errorf("Expected (3, 1), got (%v, %v)", rowMean, colMean)
+	}
+
+	empty, _ := NewGrid(11, 11, nil)
+	if _, _, ok := calculator.PositiveCentroid(empty); ok {
+		t.Error("Expected ok=false without positive cells")
+	}
+	if _, _, ok := calculator.PositiveCentroid(nil); ok {
+		t.Error("Expected ok=false for nil grid")
+	}
+}
+
+func TestNeighborhoodSizes(t *testing.T) {
+	// A corner source is starved to 6 cells; an interior one keeps all 13
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}, {Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator()
+	sizes, err := calculator.NeighborhoodSizes(grid, 2)
+	if err != nil || !slices.Equal(sizes, []int{13, 6}) {
+		t.Errorf("Expected [13 6], got %v (err=%v)", sizes, err)
+	}
+	if _, err := calculator.NeighborhoodSizes(grid, -1); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+	if _, err := calculator.NeighborhoodSizes(nil, 2); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestSourceSizeStats(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// Sizes at N=2: corner 6, edge (0,5) 9, interior 13 twice
+	grid, _ := NewGrid(11, 11, []Position{{Row: 5, Column: 5}, {Row: 0, Column: 0}, {Row: 0, Column: 5}, {Row: 7, Column: 3}})
+	minSize, maxSize, mean, median, ok := calculator.SourceSizeStats(grid, 2)
+	if !ok || minSize != 6 || maxSize != 13 || mean != 41.0/4 || median != 11 {
+		t.Errorf("Expected (6, 13, 10.25, 11, true), got (%d, %d, %v, %v, %v)", minSize, maxSize, mean, median, ok)
+	}
+
+	// A single source: every statistic equals its size
+	grid, _ = NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+	minSize, maxSize, mean, median, ok = calculator.SourceSizeStats(grid, 2)
+	if !ok || minSize != 6 || maxSize != 6 || mean != 6 || median != 6 {
+		t.Errorf("Expected all stats 6, got (%d, %d, %v, %v, %v)", minSize, maxSize, mean, median, ok)
+	}
+
+	grid, _ = NewGrid(11, 11, nil)
+	if _, _, _, _, ok := calculator.SourceSizeStats(grid, 2); ok {
+		t.Error("Expected ok=false without sources")
+	}
+}
+
+func TestPositiveHull(t *testing.T) {
+	calculator := NewNeighborhoodCalculator()
+
+	// A single source is its own hull
+	grid, _ := NewGrid(5, 5, []Position{{Row: 2, Column: 2}})
+	if hull := calculator.PositiveHull(grid); len(hull) != 1 || !hull[Position{Row: 2, Column: 2}] {
+		t.Errorf("Expected only (2,2), got %v", hull)
+	}
+
+	// Two sources on a row span a diamond between them: (2,1), (2,3), and the tips (1,2), (3,2)
+	grid, _ = NewGrid(5, 5, []Position{{Row: 2, Column: 1}, {Row: 2, Column: 3}})
+	expected := map[Position]bool{
+		{Row: 2, Column: 1}: true, {Row: 2, Column: 2}: true, {Row: 2, Column: 3}: true,
+		{Row: 1, Column: 2}: true, {Row: 3, Column: 2}: true,
+	}
+	if missing, extra := DiffCoverage(expected, calculator.PositiveHull(grid)); len(missing) != 0 || len(extra) != 0 {
+		t.Errorf("Unexpected hull: missing %v, extra %v", missing, extra)
+	}
+
+	// Sources on a diagonal yield just the diagonal segment
+	grid, _ = NewGrid(5, 5, []Position{{Row: 0, Column: 0}, {Row: 3, Column: 3}})
+	if hull := calculator.PositiveHull(grid); len(hull) != 4 || !hull[Position{Row: 2, Column: 2}] {
+		t.Errorf("Expected the 4-cell diagonal, got %v", hull)
+	}
+
+	// Parts of the region outside the grid are clipped away
+	grid, _ = NewGrid(3, 3, []Position{{Row: 0, Column: 0}, {Row: 0, Column: 2}})
+	if hull := calculator.PositiveHull(grid); len(hull) != 4 || !hull[Position{Row: 1, Column: 1}] {
+		t.Errorf("Expected 4 clipped cells, got %v", hull)
+	}
+
+	grid, _ = NewGrid(3, 3, nil)
+	if hull := calculator.PositiveHull(grid); len(hull) != 0 {
+		t.Errorf("Expected an empty hull, got %v", hull)
+	}
+}
+
+func TestPositiveHullContainsSources(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 15, 6)
+		hull := NewNeighborhoodCalculator().PositiveHull(grid)
+		for _, pos := range grid.PositiveCells {
+			if !hull[pos] {
+				t.Fatalf("Expected source %v in hull", pos)
+			}
+		}
+		for pos := range hull {
+			if !grid.IsValidPosition(pos) {
+				t.Fatalf("Hull cell %v is out of bounds", pos)
+			}
+		}
+	})
+}
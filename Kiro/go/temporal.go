@@ -0,0 +1,55 @@
+package gridneighborhoods
+
+// TimedPosition is a source that is active from Start up to, but not including, End
+type TimedPosition struct {
+	Position Position
+	Start    int
+	End      int
+}
+
+// TemporalGrid is a grid whose sources switch on and off over time
+type TemporalGrid struct {
+	Height  int
+	Width   int
+	Sources []TimedPosition
+}
+
+// NewTemporalGrid creates a temporal grid, validating dimensions, source positions, and
+// that every window has Start <= End. The sources are copied.
+func NewTemporalGrid(height, width int, sources []TimedPosition) (*TemporalGrid, error) {
+	positions := make([]Position, len(sources))
+	for i, source := range sources {
+		if source.End < source.Start {
+			return nil, &InvalidTimeWindowError{Position: source.Position, Start: source.Start, End: source.End}
+		}
+		positions[i] = source.Position
+	}
+	if _, err := NewGrid(height, width, positions); err != nil {
+		return nil, err
+	}
+	return &TemporalGrid{
+		Height:  height,
+		Width:   width,
+		Sources: append([]TimedPosition(nil), sources...),
+	}, nil
+}
+
+// ActiveAt returns a grid holding the sources active at time t, in Sources order
+func (tg *TemporalGrid) ActiveAt(t int) *Grid {
+	var active []Position
+	for _, source := range tg.Sources {
+		if source.Start <= t && t < source.End {
+			active = append(active, source.Position)
+		}
+	}
+	return &Grid{Height: tg.Height, Width: tg.Width, PositiveCells: active, positive: positiveSet(active)}
+}
+
+// CountNeighborhoodCellsAtTime counts the neighborhood union of the sources active at
+// time t. It is named apart from CountNeighborhoodCellsAt, which takes several thresholds.
+func (nc *NeighborhoodCalculator) CountNeighborhoodCellsAtTime(tg *TemporalGrid, t, distanceThreshold int) (int, error) {
+	if tg == nil {
+		return 0, ErrNilGrid
+	}
+	return nc.CountNeighborhoodCells(tg.ActiveAt(t), distanceThreshold)
+}
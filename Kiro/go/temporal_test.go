@@ -0,0 +1,59 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"testing"
+
+	. "gridneighborhoods"
+)
+
+func TestCountNeighborhoodCellsAtTime(t *testing.T) {
+	// Scenario 4 sources with staggered windows: (3,3) during [0,10), (4,5) during [5,20)
+	tg, err := NewTemporalGrid(11, 11, []TimedPosition{
+		{Position: Position{Row: 3, Column: 3}, Start: 0, End: 10},
+		{Position: Position{Row: 4, Column: 5}, Start: 5, End: 20},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	calculator := NewNeighborhoodCalculator()
+
+	tests := []struct{ time, expected int }{
+		{-1, 0},
+		{0, 13},
+		{5, 22},
+		{9, 22},
+		{10, 13},
+		{20, 0},
+	}
+	for _, tt := range tests {
+		count, err := calculator.CountNeighborhoodCellsAtTime(tg, tt.time, 2)
+		if err != nil || count != tt.expected {
+			t.Errorf("t=%d: expected %d, got %d (err=%v)", tt.time, tt.expected, count, err)
+		}
+	}
+
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := calculator.CountNeighborhoodCellsAtTime(tg, 5, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+	if _, err := calculator.CountNeighborhoodCellsAtTime(nil, 5, 2); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
+
+func TestNewTemporalGridValidation(t *testing.T) {
+	var windowErr *InvalidTimeWindowError
+	_, err := NewTemporalGrid(5, 5, []TimedPosition{{Position: Position{Row: 1, Column: 1}, Start: 4, End: 3}})
+	if !errors.As(err, &windowErr) {
+		t.Errorf("Expected InvalidTimeWindowError, got %v", err)
+	}
+	var boundsErr *PositionOutOfBoundsError
+	if _, err := NewTemporalGrid(5, 5, []TimedPosition{{Position: Position{Row: 5, Column: 0}, End: 1}}); !errors.As(err, &boundsErr) {
+		t.Errorf("Expected PositionOutOfBoundsError, got %v", err)
+	}
+	var dimensionsErr *InvalidGridDimensionsError
+	if _, err := NewTemporalGrid(0, 5, nil); !errors.As(err, &dimensionsErr) {
+		t.Errorf("Expected InvalidGridDimensionsError, got %v", err)
+	}
+}
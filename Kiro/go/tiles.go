@@ -0,0 +1,114 @@
+package gridneighborhoods
+
+// Tile is one piece of a grid split by Tiles. Its Grid covers the tile's core cells plus a
+// halo border, in local coordinates; Offset is the global position of the local (0,0).
+type Tile struct {
+	Grid   *Grid
+	Offset Position
+	// Core is the inclusive global range of cells this tile owns. Cores partition the grid.
+	Core Bounds
+}
+
+// Tiles splits the grid into cores of tileRows rows by tileCols columns each, with smaller
+// cores along the bottom and right edges when the dimensions are not multiples of the tile
+// size. Each tile extends its core by halo cells on every side (clipped to the grid) and
+// carries the positive, blocked, and excluded cells that fall inside. When halo is at least
+// the threshold, every source that can reach a core lies in its tile, so counting each
+// tile's covered core cells and summing with MergeTileCounts reproduces
+// CountNeighborhoodCells on the whole grid. Tiling assumes clipped edges; wrapped
+// topologies are not preserved.
+func Tiles(grid *Grid, tileRows, tileCols, halo int) ([]Tile, error) {
+	if grid == nil {
+		return nil, ErrNilGrid
+	}
+	if tileRows <= 0 || tileCols <= 0 {
+		return nil, &InvalidBlockSizeError{Rows: tileRows, Columns: tileCols}
+	}
+	if halo < 0 {
+		return nil, &InvalidDistanceThresholdError{Threshold: halo}
+	}
+	// A halo past the grid's extent reaches nothing more, and clamping keeps sums in range
+	halo = min(halo, grid.MaxManhattanDistance())
+
+	var tiles []Tile
+	for coreRow := 0; coreRow < grid.Height; coreRow += tileRows {
+		for coreCol := 0; coreCol < grid.Width; coreCol += tileCols {
+			core := Bounds{
+				Min: Position{Row: coreRow, Column: coreCol},
+				Max: Position{Row: min(grid.Height-1, coreRow+tileRows-1), Column: min(grid.Width-1, coreCol+tileCols-1)},
+			}
+			tile, err := extractTile(grid, core, halo)
+			if err != nil {
+				return nil, err
+			}
+			tiles = append(tiles, tile)
+		}
+	}
+	return tiles, nil
+}
+
+// extractTile builds the tile owning core, with a halo-wide border
+func extractTile(grid *Grid, core Bounds, halo int) (Tile, error) {
+	extent := Bounds{
+		Min: Position{Row: max(0, core.Min.Row-halo), Column: max(0, core.Min.Column-halo)},
+		Max: Position{Row: min(grid.Height-1, core.Max.Row+halo), Column: min(grid.Width-1, core.Max.Column+halo)},
+	}
+	inExtent := func(pos Position) bool {
+		return pos.Row >= extent.Min.Row && pos.Row <= extent.Max.Row &&
+			pos.Column >= extent.Min.Column && pos.Column <= extent.Max.Column
+	}
+
+	var positives []Position
+	for _, pos := range grid.PositiveCells {
+		if inExtent(pos) {
+			positives = append(positives, pos.Sub(extent.Min))
+		}
+	}
+	local, err := NewGrid(extent.Max.Row-extent.Min.Row+1, extent.Max.Column-extent.Min.Column+1, positives)
+	if err != nil {
+		return Tile{}, err
+	}
+	local.Origin = grid.Origin
+	for pos := range grid.blocked {
+		if inExtent(pos) {
+			if local.blocked == nil {
+				local.blocked = make(map[Position]bool)
+			}
+			local.blocked[pos.Sub(extent.Min)] = true
+		}
+	}
+	for _, pos := range grid.Excluded {
+		if inExtent(pos) {
+			local.Excluded = append(local.Excluded, pos.Sub(extent.Min))
+		}
+	}
+	return Tile{Grid: local, Offset: extent.Min, Core: core}, nil
+}
+
+// CoreCount counts the cells of localCoverage, a coverage set computed on t.Grid, that lie
+// in the tile's core. Halo cells are left to the tiles that own them.
+func (t Tile) CoreCount(localCoverage map[Position]bool) int {
+	count := 0
+	for pos, ok := range localCoverage {
+		global := t.Offset.Add(pos)
+		if ok && global.Row >= t.Core.Min.Row && global.Row <= t.Core.Max.Row &&
+			global.Column >= t.Core.Min.Column && global.Column <= t.Core.Max.Column {
+			count++
+		}
+	}
+	return count
+}
+
+// MergeTileCounts combines per-tile coverage sets, where coverages[i] was computed on
+// tiles[i].Grid, into the global covered-cell count. Each cell is counted only by the tile
+// whose core owns it, so halo overlap is never double-counted.
+func MergeTileCounts(tiles []Tile, coverages []map[Position]bool) (int, error) {
+	if len(tiles) != len(coverages) {
+		return 0, &TileCountMismatchError{Tiles: len(tiles), Coverages: len(coverages)}
+	}
+	total := 0
+	for i, tile := range tiles {
+		total += tile.CoreCount(coverages[i])
+	}
+	return total, nil
+}
@@ -0,0 +1,93 @@
+package gridneighborhoods_test
+
+import (
+	"errors"
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestTilesScenario4(t *testing.T) {
+	grid, _ := NewGrid(11, 11, []Position{{Row: 3, Column: 3}, {Row: 4, Column: 5}})
+	tiles, err := Tiles(grid, 4, 6, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Cores of 4 rows (4, 4, 3) by 6 columns (6, 5)
+	if len(tiles) != 6 {
+		t.Fatalf("Expected 6 tiles, got %d", len(tiles))
+	}
+
+	first := tiles[0]
+	if first.Offset != (Position{}) || first.Core != (Bounds{Max: Position{Row: 3, Column: 5}}) {
+		t.Errorf("Unexpected first tile offset %v and core %+v", first.Offset, first.Core)
+	}
+	if first.Grid.Height != 6 || first.Grid.Width != 8 {
+		t.Errorf("Expected a 6x8 first tile, got %dx%d", first.Grid.Height, first.Grid.Width)
+	}
+	// The last tile's halo reaches back two rows and columns from its core
+	last := tiles[len(tiles)-1]
+	if last.Offset != (Position{Row: 6, Column: 4}) || last.Grid.Height != 5 || last.Grid.Width != 7 {
+		t.Errorf("Unexpected last tile offset %v and size %dx%d", last.Offset, last.Grid.Height, last.Grid.Width)
+	}
+
+	calculator := NewNeighborhoodCalculator()
+	coverages := make([]map[Position]bool, len(tiles))
+	for i, tile := range tiles {
+		coverages[i] = calculator.GetNeighborhoodCells(tile.Grid, 2)
+	}
+	if total, err := MergeTileCounts(tiles, coverages); err != nil || total != 22 {
+		t.Errorf("Expected merged count 22, got %d (err=%v)", total, err)
+	}
+
+	var mismatchErr *TileCountMismatchError
+	if _, err := MergeTileCounts(tiles, coverages[1:]); !errors.As(err, &mismatchErr) {
+		t.Errorf("Expected TileCountMismatchError, got %v", err)
+	}
+}
+
+func TestTilesMergeMatchesWholeGrid(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 20, 6)
+		if rapid.Bool().Draw(t, "blocked") {
+			row := rapid.IntRange(0, grid.Height-1).Draw(t, "blocked_row")
+			grid.AddBlockedRect(row, 0, row, grid.Width/2)
+		}
+		tileRows := rapid.IntRange(1, 8).Draw(t, "tileRows")
+		tileCols := rapid.IntRange(1, 8).Draw(t, "tileCols")
+		distanceThreshold := rapid.IntRange(0, 12).Draw(t, "distanceThreshold")
+		halo := distanceThreshold + rapid.IntRange(0, 2).Draw(t, "extraHalo")
+
+		tiles, err := Tiles(grid, tileRows, tileCols, halo)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		calculator := NewNeighborhoodCalculator()
+		coverages := make([]map[Position]bool, len(tiles))
+		for i, tile := range tiles {
+			coverages[i] = calculator.GetNeighborhoodCells(tile.Grid, distanceThreshold)
+		}
+		total, _ := MergeTileCounts(tiles, coverages)
+		expected, _ := calculator.CountNeighborhoodCells(grid, distanceThreshold)
+		if total != expected {
+			t.Fatalf("Expected %d, got %d", expected, total)
+		}
+	})
+}
+
+func TestTilesValidation(t *testing.T) {
+	grid, _ := NewGrid(5, 5, nil)
+	var blockErr *InvalidBlockSizeError
+	if _, err := Tiles(grid, 0, 2, 1); !errors.As(err, &blockErr) {
+		t.Errorf("Expected InvalidBlockSizeError, got %v", err)
+	}
+	var thresholdErr *InvalidDistanceThresholdError
+	if _, err := Tiles(grid, 2, 2, -1); !errors.As(err, &thresholdErr) {
+		t.Errorf("Expected InvalidDistanceThresholdError, got %v", err)
+	}
+	if _, err := Tiles(nil, 2, 2, 1); !errors.Is(err, ErrNilGrid) {
+		t.Errorf("Expected ErrNilGrid, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package gridneighborhoods
+
+// Topology selects which grid axes wrap around. Wrapping rows joins row Height-1 back to
+// row 0 and wrapping columns joins column Width-1 back to column 0; wrapping one axis gives
+// a cylinder and wrapping both gives a torus. The zero Topology is a bounded rectangle.
+type Topology struct {
+	WrapRows    bool
+	WrapColumns bool
+}
+
+// WithTopology makes the calculator enumerate Manhattan neighborhoods and measure
+// distances on the given topology. The chamfer and weighted metrics stay bounded.
+func WithTopology(topology Topology) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.topology = topology
+	}
+}
+
+// BoundaryMode selects what happens to the part of a neighborhood that crosses a grid edge
+type BoundaryMode int
+
+const (
+	// Clip drops cells beyond the edges; this is the default
+	Clip BoundaryMode = iota
+	// Reflect folds cells beyond an edge back inward, mirrored about the edge row or column.
+	// A mirrored cell is always nearer its source than the cell it mirrors, so it already
+	// lies in the clipped neighborhood: counts and cell sets equal Clip's. The mirrored
+	// hits do add to CoverageMultiplicity, and with it to Analyze's OverlapCount and to
+	// CoverageScore, so edge cells can be covered more than once by a single source. Hits
+	// are counted as if the threshold were at most the source's farthest in-grid distance.
+	Reflect
+	// Wrap joins opposite edges into a torus, the same as WithTopology with both axes
+	// wrapped. Cells cut off at one edge reappear at the other, so counts can only grow.
+	Wrap
+)
+
+// WithBoundaryMode selects how neighborhoods behave at grid edges. Clip and Reflect leave
+// both axes unwrapped; Wrap wraps both. It replaces any earlier WithTopology setting.
+func WithBoundaryMode(mode BoundaryMode) CalculatorOption {
+	return func(nc *NeighborhoodCalculator) {
+		nc.boundaryMode = mode
+		nc.topology = Topology{WrapRows: mode == Wrap, WrapColumns: mode == Wrap}
+	}
+}
+
+// forEachReflectedHit calls visit for every cell of center's full diamond, folding cells
+// beyond the edges back into the grid. Blocked cells are skipped. Cells reached both
+// directly and through a reflection are visited once per hit.
+func (nc *NeighborhoodCalculator) forEachReflectedHit(grid *Grid, center Position, distanceThreshold int, visit func(Position)) {
+	// Reflected hits keep accumulating as the threshold grows, so the threshold is capped at
+	// the reach that already covers the whole grid; this keeps huge thresholds finite
+	farthest := max(center.Row, grid.Height-1-center.Row) + max(center.Column, grid.Width-1-center.Column)
+	distanceThreshold = min(distanceThreshold, farthest)
+	for deltaRow := -distanceThreshold; deltaRow <= distanceThreshold; deltaRow++ {
+		row := reflectCoordinate(center.Row+deltaRow, grid.Height)
+		remainingDistance := distanceThreshold - Abs(deltaRow)
+		for deltaCol := -remainingDistance; deltaCol <= remainingDistance; deltaCol++ {
+			pos := Position{Row: row, Column: reflectCoordinate(center.Column+deltaCol, grid.Width)}
+			if !grid.IsBlocked(pos) {
+				visit(pos)
+			}
+		}
+	}
+}
+
+// reflectCoordinate folds a coordinate into [0, extent) by mirroring about the first and
+// last cells, so extent maps to extent-2 and -1 maps to 1
+func reflectCoordinate(coordinate, extent int) int {
+	if extent == 1 {
+		return 0
+	}
+	period := 2 * (extent - 1)
+	coordinate = wrapCoordinate(coordinate, period)
+	if coordinate >= extent {
+		coordinate = period - coordinate
+	}
+	return coordinate
+}
+
+// CalculateWrappedManhattanDistance computes the Manhattan distance between two positions
+// on a height x width grid, taking the shorter way around each wrapped axis
+func (dc *DistanceCalculator) CalculateWrappedManhattanDistance(pos1, pos2 Position, height, width int, topology Topology) int {
+	rowDiff := Abs(pos1.Row - pos2.Row)
+	if topology.WrapRows {
+		rowDiff %= height
+		rowDiff = min(rowDiff, height-rowDiff)
+	}
+	colDiff := Abs(pos1.Column - pos2.Column)
+	if topology.WrapColumns {
+		colDiff %= width
+		colDiff = min(colDiff, width-colDiff)
+	}
+	return rowDiff + colDiff
+}
+
+// gridDistance is the Manhattan distance under the calculator's topology
+func (nc *NeighborhoodCalculator) gridDistance(grid *Grid, pos1, pos2 Position) int {
+	return nc.distanceCalculator.CalculateWrappedManhattanDistance(pos1, pos2, grid.Height, grid.Width, nc.topology)
+}
+
+// wrapPosition maps pos onto the grid along wrapped axes and reports whether the result
+// lies within the grid
+func (nc *NeighborhoodCalculator) wrapPosition(grid *Grid, pos Position) (Position, bool) {
+	if nc.topology.WrapRows {
+		pos.Row = wrapCoordinate(pos.Row, grid.Height)
+	}
+	if nc.topology.WrapColumns {
+		pos.Column = wrapCoordinate(pos.Column, grid.Width)
+	}
+	return pos, nc.boundaryHandler.IsWithinBounds(pos, grid)
+}
+
+// wrapCoordinate reduces a coordinate into [0, extent)
+func wrapCoordinate(coordinate, extent int) int {
+	return ((coordinate % extent) + extent) % extent
+}
+
+// axisReach returns the range of deltas from coordinate along an axis of the given extent
+// that stay within reach. Without wrapping the range is clipped to the axis; with wrapping
+// every delta lands on a distinct cell and its absolute value is the wrapped distance.
+func axisReach(coordinate, extent, reach int, wrap bool) (int, int) {
+	if wrap {
+		return -min(reach, (extent-1)/2), min(reach, extent/2)
+	}
+	return max(-reach, -coordinate), min(reach, extent-1-coordinate)
+}
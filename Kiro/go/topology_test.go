@@ -0,0 +1,145 @@
+package gridneighborhoods_test
+
+import (
+	"testing"
+
+	. "gridneighborhoods"
+
+	"pgregory.net/rapid"
+)
+
+func TestWrappedTopologyCounts(t *testing.T) {
+	// Scenario 3: a corner source with N=2 keeps 6 of its 13 diamond cells when clipped
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+
+	tests := []struct {
+		name     string
+		topology Topology
+		expected int
+	}{
+		{"bounded", Topology{}, 6},
+		{"wrap columns", Topology{WrapColumns: true}, 9},
+		{"wrap rows", Topology{WrapRows: true}, 9},
+		{"torus", Topology{WrapRows: true, WrapColumns: true}, 13},
+	}
+	for _, tt := range tests {
+		calculator := NewNeighborhoodCalculator(WithTopology(tt.topology))
+		if count, err := calculator.CountNeighborhoodCells(grid, 2); err != nil || count != tt.expected {
+			t.Errorf("%s: expected %d, got %d (err=%v)", tt.name, tt.expected, count, err)
+		}
+	}
+
+	// A wrapped diamond wider than the grid covers each column once
+	strip, _ := NewGrid(1, 4, []Position{{Row: 0, Column: 0}})
+	calculator := NewNeighborhoodCalculator(WithTopology(Topology{WrapColumns: true}))
+	if cells := calculator.EnumerateNeighborhoodOrdered(strip, Position{Row: 0, Column: 0}, 3); len(cells) != 4 {
+		t.Errorf("Expected 4 distinct cells, got %v", cells)
+	}
+}
+
+func TestWrappedManhattanDistance(t *testing.T) {
+	dc := NewDistanceCalculator()
+	a, b := Position{Row: 0, Column: 1}, Position{Row: 9, Column: 8}
+	tests := []struct {
+		topology Topology
+		expected int
+	}{
+		{Topology{}, 16},
+		{Topology{WrapRows: true}, 8},
+		{Topology{WrapColumns: true}, 12},
+		{Topology{WrapRows: true, WrapColumns: true}, 4},
+	}
+	for _, tt := range tests {
+		if got := dc.CalculateWrappedManhattanDistance(a, b, 10, 10, tt.topology); got != tt.expected {
+			t.Errorf("%+v: expected %d, got %d", tt.topology, tt.expected, got)
+		}
+	}
+}
+
+func TestWrappedTopologyMatchesBruteForce(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 12, 4)
+		threshold := rapid.IntRange(0, 12).Draw(t, "threshold")
+		topology := Topology{
+			WrapRows:    rapid.Bool().Draw(t, "wrapRows"),
+			WrapColumns: rapid.Bool().Draw(t, "wrapColumns"),
+		}
+
+		calculator := NewNeighborhoodCalculator(WithTopology(topology))
+		dc := NewDistanceCalculator()
+		expected := bruteForceCount(grid, func(source, cell Position) bool {
+			return dc.CalculateWrappedManhattanDistance(source, cell, grid.Height, grid.Width, topology) <= threshold
+		})
+		count, err := calculator.CountNeighborhoodCells(grid, threshold)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if count != expected {
+			t.Fatalf("Expected %d, got %d", expected, count)
+		}
+
+		// The breadth-first distance layers must agree with enumeration
+		counts, _ := calculator.CountNeighborhoodCellsRange(grid, threshold)
+		if counts[threshold] != expected {
+			t.Fatalf("Range: expected %d, got %d", expected, counts[threshold])
+		}
+	})
+}
+
+func TestBoundaryModes(t *testing.T) {
+	// Scenario 3: a corner source with N=2 keeps 6 of its 13 diamond cells when clipped
+	grid, _ := NewGrid(11, 11, []Position{{Row: 0, Column: 0}})
+
+	for mode, expected := range map[BoundaryMode]int{Clip: 6, Reflect: 6, Wrap: 13} {
+		calculator := NewNeighborhoodCalculator(WithBoundaryMode(mode))
+		if count, _ := calculator.CountNeighborhoodCells(grid, 2); count != expected {
+			t.Errorf("Mode %d: expected %d, got %d", mode, expected, count)
+		}
+	}
+
+	// Reflect folds the 7 clipped cells back in: (-1,0)->(1,0), (0,-1)->(0,1),
+	// (-2,0)->(2,0), (0,-2)->(0,2), (-1,1)->(1,1), (1,-1)->(1,1), (-1,-1)->(1,1)
+	reflect := NewNeighborhoodCalculator(WithBoundaryMode(Reflect))
+	multiplicity := reflect.CoverageMultiplicity(grid, 2)
+	expected := map[Position]int{
+		{Row: 0, Column: 0}: 1,
+		{Row: 1, Column: 0}: 2, {Row: 0, Column: 1}: 2,
+		{Row: 2, Column: 0}: 2, {Row: 0, Column: 2}: 2,
+		{Row: 1, Column: 1}: 4,
+	}
+	total := 0
+	for pos, hits := range multiplicity {
+		total += hits
+		if hits != expected[pos] {
+			t.Errorf("Cell %v: expected %d hits, got %d", pos, expected[pos], hits)
+		}
+	}
+	if len(multiplicity) != 6 || total != 13 {
+		t.Errorf("Expected 13 hits over 6 cells, got %d over %d", total, len(multiplicity))
+	}
+
+	// Clip only counts each source once per cell
+	for pos, hits := range NewNeighborhoodCalculator().CoverageMultiplicity(grid, 2) {
+		if hits != 1 {
+			t.Errorf("Clip: cell %v has %d hits", pos, hits)
+		}
+	}
+}
+
+func TestReflectMatchesClipCounts(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		grid := drawGrid(t, 10, 3)
+		threshold := rapid.IntRange(0, 25).Draw(t, "threshold")
+
+		clipped := NewNeighborhoodCalculator().CoverageMultiplicity(grid, threshold)
+		reflected := NewNeighborhoodCalculator(WithBoundaryMode(Reflect)).CoverageMultiplicity(grid, threshold)
+		if len(clipped) != len(reflected) {
+			t.Fatalf("Expected %d covered cells, got %d", len(clipped), len(reflected))
+		}
+		for pos, hits := range clipped {
+			if reflected[pos] < hits {
+				t.Fatalf("Cell %v: reflected hits %d below clipped %d", pos, reflected[pos], hits)
+			}
+		}
+	})
+}